@@ -0,0 +1,247 @@
+package psubcommands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/pflag"
+)
+
+// SetAlias defines name as alias for the command line expansion, e.g.
+// SetAlias("co", "checkout --quiet"). The expansion is split into words like
+// a shell would. Registered commands take precedence over aliases. The name
+// must not be empty or contain "=" or white space, the expansion must be a
+// single line.
+func (c *Commander) SetAlias(name, expansion string) error {
+	if name == "" || strings.Contains(name, "=") || strings.ContainsFunc(name, unicode.IsSpace) {
+		return fmt.Errorf("alias %q: invalid name", name)
+	}
+	if strings.ContainsAny(expansion, "\r\n") {
+		return fmt.Errorf("alias %s: expansion must be a single line", name)
+	}
+	words, err := splitWords(expansion)
+	if err != nil {
+		return fmt.Errorf("alias %s: %w", name, err)
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("alias %s: empty expansion", name)
+	}
+
+	if c.aliases == nil {
+		c.aliases = map[string]string{}
+	}
+	c.aliases[name] = expansion
+	return nil
+}
+
+// RemoveAlias removes the alias name. It returns false if no such alias exists.
+func (c *Commander) RemoveAlias(name string) bool {
+	if _, ok := c.aliases[name]; !ok {
+		return false
+	}
+	delete(c.aliases, name)
+	return true
+}
+
+// Aliases returns a copy of all defined aliases.
+func (c *Commander) Aliases() map[string]string {
+	aliases := make(map[string]string, len(c.aliases))
+	for k, v := range c.aliases {
+		aliases[k] = v
+	}
+	return aliases
+}
+
+// LoadAliases reads aliases from the file at path. Every line has the form
+// "name = expansion", empty lines and lines starting with # are ignored.
+// A missing file is not an error. The alias command saves changes to path.
+func (c *Commander) LoadAliases(path string) error {
+	c.aliasFile = path
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	return c.ReadAliases(file)
+}
+
+// ReadAliases reads aliases in the format described at LoadAliases from r.
+func (c *Commander) ReadAliases(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("line %d: expected name = expansion", line)
+		}
+		if err := c.SetAlias(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// WriteAliases writes all aliases to w in the format read by ReadAliases.
+func (c *Commander) WriteAliases(w io.Writer) error {
+	for _, name := range sortedKeys(c.aliases) {
+		if _, err := fmt.Fprintf(w, "%s = %s\n", name, c.aliases[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveAliases replaces the file at path with all aliases.
+func (c *Commander) SaveAliases(path string) error {
+	return replaceFile(path, c.WriteAliases)
+}
+
+// replaceFile atomically replaces the file at path with the content written
+// by write, creating its directory if necessary.
+func replaceFile(path string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// expandAlias replaces the first element of argv with its alias expansion.
+func (c *Commander) expandAlias(argv []string) []string {
+	if len(argv) == 0 {
+		return argv
+	}
+	if _, ok := c.Lookup(argv[0]); ok {
+		return argv
+	}
+	expansion, ok := c.aliases[argv[0]]
+	if !ok {
+		return argv
+	}
+
+	words, _ := splitWords(expansion)
+	return append(words, argv[1:]...)
+}
+
+func (c *Commander) explainAliases(w io.Writer) {
+	if len(c.aliases) == 0 {
+		return
+	}
+	fmt.Fprint(w, c.tr("Aliases:\n"))
+	for _, name := range sortedKeys(c.aliases) {
+		fmt.Fprintf(w, "\t%-15s    %s\n", name, c.aliases[name])
+	}
+	fmt.Fprintln(w)
+}
+
+// SetAlias defines an alias on the DefaultCommander.
+func SetAlias(name, expansion string) error { return DefaultCommander.SetAlias(name, expansion) }
+
+// LoadAliases reads aliases from the file at path into the DefaultCommander.
+func LoadAliases(path string) error { return DefaultCommander.LoadAliases(path) }
+
+type aliasCommand Commander
+
+// Name of this command.
+func (*aliasCommand) Name() string { return "alias" }
+
+// Synopsis returns a short description of this command.
+func (a *aliasCommand) Synopsis() string {
+	return (*Commander)(a).tr("list, set or delete command aliases")
+}
+
+// Usage returns the long description of this command.
+func (a *aliasCommand) Usage() string {
+	return (*Commander)(a).tr("Actions:\n  list                       list all aliases\n  set <name> <expansion...>  define name as alias for expansion\n  delete <name>              delete an alias")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*aliasCommand) SetFlags(*pflag.FlagSet) {}
+
+// Args returns the positional arguments of this command.
+func (*aliasCommand) Args() ArgSpec {
+	return ArgSpec{Names: []string{"action", "args"}, Min: 1, Max: -1}
+}
+
+// Complete returns the actions and alias names.
+func (a *aliasCommand) Complete(name, _ string) []string {
+	if name != "" {
+		return nil
+	}
+	return append([]string{"list", "set", "delete"}, sortedKeys(a.aliases)...)
+}
+
+// Execute executs this command and returns it's ExitStatus.
+func (a *aliasCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	c := (*Commander)(a)
+	args := f.Args()
+	set := args[0] == "set" && len(args) >= 3
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		c.WriteAliases(Streams(ctx).Out)
+		return ExitSuccess
+	case !set && (args[0] != "delete" || len(args) != 2):
+		return UsageErrorf(f, c.tr("Invalid alias action"))
+	case c.aliasFile == "":
+		fmt.Fprintln(Streams(ctx).Err, c.tr("Aliases can't be saved, no alias file was loaded"))
+		return ExitFailure
+	}
+
+	// Changes are only kept if they were saved.
+	name := args[1]
+	old, existed := c.aliases[name]
+	if set {
+		if _, ok := c.Lookup(name); ok {
+			return UsageErrorf(f, c.tr("%s is a command and can't be an alias"), name)
+		}
+		if err := c.SetAlias(name, strings.Join(args[2:], " ")); err != nil {
+			return UsageErrorf(f, "%s", err)
+		}
+	} else if !c.RemoveAlias(name) {
+		fmt.Fprintf(Streams(ctx).Err, c.tr("Alias %s not defined\n"), name)
+		return ExitFailure
+	}
+
+	if err := c.SaveAliases(c.aliasFile); err != nil {
+		if existed {
+			c.aliases[name] = old
+		} else {
+			delete(c.aliases, name)
+		}
+		fmt.Fprintf(Streams(ctx).Err, c.tr("Failed to save aliases: %s\n"), err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// RegisterAliasCommand registers the "alias" command to the specified group.
+// It lists, sets and deletes aliases and saves changes to the file read by
+// LoadAliases.
+func (c *Commander) RegisterAliasCommand(group string) { c.Register(group, (*aliasCommand)(c)) }
+
+// RegisterAliasCommand registers the alias command on the DefaultCommander.
+func RegisterAliasCommand(group string) { DefaultCommander.RegisterAliasCommand(group) }
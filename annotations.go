@@ -0,0 +1,44 @@
+package psubcommands
+
+// Annotator may be implemented by a Command to carry machine-readable
+// metadata like "requires-auth" or "stability" for middleware, help
+// templates and documentation generators.
+type Annotator interface {
+	Annotations() map[string]string
+}
+
+// Annotate attaches the annotation key=value to the command with the
+// specified name, overriding annotations of the command itself.
+func (c *Commander) Annotate(name, key, value string) {
+	if c.annotations == nil {
+		c.annotations = map[string]map[string]string{}
+	}
+	if c.annotations[name] == nil {
+		c.annotations[name] = map[string]string{}
+	}
+	c.annotations[name][key] = value
+}
+
+// Annotations returns all annotations of cmd, combining the ones provided
+// by cmd itself with the ones attached with Annotate.
+func (c *Commander) Annotations(cmd Command) map[string]string {
+	annotations := map[string]string{}
+	if a, ok := unwrap(cmd).(Annotator); ok {
+		for k, v := range a.Annotations() {
+			annotations[k] = v
+		}
+	}
+	for k, v := range c.annotations[cmd.Name()] {
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// Annotation returns the value of the annotation key of cmd.
+func (c *Commander) Annotation(cmd Command, key string) (string, bool) {
+	v, ok := c.Annotations(cmd)[key]
+	return v, ok
+}
+
+// Annotate attaches an annotation to a command of the DefaultCommander.
+func Annotate(name, key, value string) { DefaultCommander.Annotate(name, key, value) }
@@ -0,0 +1,64 @@
+package psubcommands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandArgFiles expands argument files in argv if ArgumentFiles is enabled.
+func (c *Commander) expandArgFiles(argv []string) ([]string, error) {
+	if !c.ArgumentFiles {
+		return argv, nil
+	}
+	return expandArgFiles(argv)
+}
+
+// expandArgFiles replaces every argument of the form @path with the arguments
+// read from path, one per line. Empty lines and lines starting with # are
+// skipped. An argument starting with @@ is passed on with one @ removed.
+// Arguments following "--" are not expanded.
+func expandArgFiles(argv []string) ([]string, error) {
+	expanded := make([]string, 0, len(argv))
+	for i, arg := range argv {
+		switch {
+		case arg == "--":
+			return append(expanded, argv[i:]...), nil
+		case strings.HasPrefix(arg, "@@"):
+			expanded = append(expanded, arg[1:])
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			args, err := readArgFile(arg[1:])
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, args...)
+		default:
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+func readArgFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	args := []string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args = append(args, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return args, nil
+}
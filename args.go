@@ -0,0 +1,202 @@
+package psubcommands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ArgCompletion tells shell completion how to complete positional arguments.
+type ArgCompletion int
+
+const (
+	// CompleteDefault completes positional arguments using Completer only.
+	CompleteDefault ArgCompletion = iota
+	// CompleteFiles additionally falls back to native file completion.
+	CompleteFiles
+	// CompleteDirs additionally falls back to native directory completion.
+	CompleteDirs
+)
+
+// ArgSpec describes the positional arguments a command expects.
+type ArgSpec struct {
+	// Names of the positional arguments used in usage strings.
+	Names []string
+
+	// Min is the minimum number of positional arguments.
+	Min int
+
+	// Max is the maximum number of positional arguments.
+	// A negative value allows an unlimited number of arguments.
+	Max int
+
+	// Values binds the positional arguments to typed values. Value i
+	// receives argument i, the last value receives all remaining arguments.
+	Values []pflag.Value
+
+	// PassThrough names the arguments following "--" in usage strings.
+	// If set, arguments after "--" are neither validated nor bound but
+	// handed to the command untouched, see PassThrough.
+	PassThrough string
+
+	// Completion tells shell completion how to complete the arguments.
+	Completion ArgCompletion
+
+	// Extensions limits CompleteFiles to files with these extensions.
+	Extensions []string
+
+	// Stdin names the argument which may be "-" to read standard input
+	// instead of a file, see OpenInput. Usage strings show it as <name|->
+	// and "-" is accepted at most once.
+	Stdin string
+}
+
+// ArgSpecer may be implemented by a Command to declare its positional arguments.
+// The Commander validates the arguments before the command is executed.
+type ArgSpecer interface {
+	Args() ArgSpec
+}
+
+// StrictArgser may be implemented by a Command without ArgSpec to reject
+// positional arguments instead of silently ignoring them.
+type StrictArgser interface {
+	StrictArgs() bool
+}
+
+// String returns the usage representation of the arguments, e.g. "<src> <dst> [files...]".
+func (s ArgSpec) String() string {
+	parts := make([]string, 0, len(s.Names))
+	for i, name := range s.Names {
+		if name == s.Stdin {
+			name += "|" + StdinArg
+		}
+		if s.Max < 0 && i == len(s.Names)-1 {
+			name += "..."
+		}
+		if i < s.Min {
+			parts = append(parts, fmt.Sprintf("<%s>", name))
+		} else {
+			parts = append(parts, fmt.Sprintf("[%s]", name))
+		}
+	}
+	if s.PassThrough != "" {
+		parts = append(parts, fmt.Sprintf("[-- %s...]", s.PassThrough))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Validate checks if args satisfies the ArgSpec.
+func (s ArgSpec) Validate(args []string) error { return s.validate(identity, args) }
+
+func (s ArgSpec) validate(tr func(string) string, args []string) error {
+	switch {
+	case len(args) < s.Min:
+		return fmt.Errorf(tr("expected at least %d argument(s), got %d"), s.Min, len(args))
+	case s.Max >= 0 && len(args) > s.Max:
+		return fmt.Errorf(tr("expected at most %d argument(s), got %d, unexpected: %s"),
+			s.Max, len(args), strings.Join(args[s.Max:], " "))
+	}
+
+	if s.Stdin == "" || len(s.Names) == 0 {
+		return nil
+	}
+	stdin := 0
+	for i, arg := range args {
+		if arg != StdinArg {
+			continue
+		}
+		if s.name(min(i, len(s.Names)-1)) != s.Stdin {
+			return fmt.Errorf(tr("argument %s doesn't accept %s"), s.name(i), StdinArg)
+		}
+		if stdin++; stdin > 1 {
+			return errors.New(tr("standard input can only be read once"))
+		}
+	}
+	return nil
+}
+
+// Bind sets the Values of the ArgSpec from args.
+func (s ArgSpec) Bind(args []string) error { return s.bind(identity, args) }
+
+func (s ArgSpec) bind(tr func(string) string, args []string) error {
+	for i, arg := range args {
+		if len(s.Values) == 0 {
+			break
+		}
+
+		idx := i
+		if idx >= len(s.Values) {
+			idx = len(s.Values) - 1
+		}
+		if err := s.Values[idx].Set(arg); err != nil {
+			return fmt.Errorf(tr("invalid argument %s: %s"), s.name(idx), err)
+		}
+	}
+	return nil
+}
+
+func (s ArgSpec) name(i int) string {
+	if i < len(s.Names) {
+		return s.Names[i]
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// argSpec returns the ArgSpec of cmd and whether cmd declares one.
+func argSpec(cmd Command) (ArgSpec, bool) {
+	if a, ok := cmd.(ArgSpecer); ok {
+		return a.Args(), true
+	}
+	return ArgSpec{}, false
+}
+
+func strictArgs(cmd Command) bool {
+	s, ok := cmd.(StrictArgser)
+	return ok && s.StrictArgs()
+}
+
+// checkArgs validates the positional arguments of cmd.
+func (c *Commander) checkArgs(cmd Command, f *pflag.FlagSet) ExitStatus {
+	spec, ok := argSpec(cmd)
+	if !ok && !strictArgs(cmd) {
+		return ExitSuccess
+	}
+
+	args := f.Args()
+	if spec.PassThrough != "" && f.ArgsLenAtDash() >= 0 {
+		args = args[:f.ArgsLenAtDash()]
+	}
+
+	err := spec.validate(c.tr, args)
+	if err == nil {
+		err = spec.bind(c.tr, args)
+	}
+	if err != nil {
+		fmt.Fprintf(f.Output(), c.tr("Subcommand %s: %s\n\n"), cmd.Name(), err)
+		c.ExplainCommand(f.Output(), cmd)
+		return ExitUsageError
+	}
+	return ExitSuccess
+}
+
+// argsUsage returns the usage representation of the positional arguments of cmd.
+func argsUsage(cmd Command) string {
+	if spec, ok := argSpec(cmd); ok {
+		if usage := spec.String(); usage != "" {
+			return " " + usage
+		}
+	}
+	return ""
+}
+
+// PassThrough returns all arguments following "--" on the command line
+// of f untouched, including arguments looking like flags. It returns nil
+// if the command line didn't contain "--".
+func PassThrough(f *pflag.FlagSet) []string {
+	if f.ArgsLenAtDash() < 0 {
+		return nil
+	}
+	return f.Args()[f.ArgsLenAtDash():]
+}
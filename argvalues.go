@@ -0,0 +1,99 @@
+package psubcommands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type stringArg struct{ p *string }
+
+// StringArg returns a pflag.Value binding a positional argument to p.
+func StringArg(p *string) pflag.Value { return &stringArg{p} }
+
+func (a *stringArg) String() string     { return *a.p }
+func (a *stringArg) Type() string       { return "string" }
+func (a *stringArg) Set(v string) error { *a.p = v; return nil }
+
+type intArg struct{ p *int }
+
+// IntArg returns a pflag.Value binding a positional argument to p.
+func IntArg(p *int) pflag.Value { return &intArg{p} }
+
+func (a *intArg) String() string { return strconv.Itoa(*a.p) }
+func (a *intArg) Type() string   { return "int" }
+func (a *intArg) Set(v string) error {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", v)
+	}
+	*a.p = i
+	return nil
+}
+
+type durationArg struct{ p *time.Duration }
+
+// DurationArg returns a pflag.Value binding a positional argument to p.
+func DurationArg(p *time.Duration) pflag.Value { return &durationArg{p} }
+
+func (a *durationArg) String() string { return a.p.String() }
+func (a *durationArg) Type() string   { return "duration" }
+func (a *durationArg) Set(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration", v)
+	}
+	*a.p = d
+	return nil
+}
+
+type fileArg struct{ p *string }
+
+// FileArg returns a pflag.Value binding a positional argument to p.
+// The argument must name an existing file or directory.
+func FileArg(p *string) pflag.Value { return &fileArg{p} }
+
+func (a *fileArg) String() string { return *a.p }
+func (a *fileArg) Type() string   { return "file" }
+func (a *fileArg) Set(v string) error {
+	if _, err := os.Stat(v); err != nil {
+		return err
+	}
+	*a.p = v
+	return nil
+}
+
+type enumArg struct {
+	p       *string
+	allowed []string
+}
+
+// EnumArg returns a pflag.Value binding a positional argument to p.
+// The argument must be one of allowed.
+func EnumArg(p *string, allowed ...string) pflag.Value { return &enumArg{p, allowed} }
+
+func (a *enumArg) String() string { return *a.p }
+func (a *enumArg) Type() string   { return "enum" }
+func (a *enumArg) Set(v string) error {
+	for _, allowed := range a.allowed {
+		if v == allowed {
+			*a.p = v
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must be one of %s", v, strings.Join(a.allowed, ", "))
+}
+
+type stringsArg struct{ p *[]string }
+
+// StringsArg returns a pflag.Value appending every positional argument it
+// receives to p. Use it as last value of an ArgSpec with unlimited arguments.
+func StringsArg(p *[]string) pflag.Value { return &stringsArg{p} }
+
+func (a *stringsArg) String() string     { return strings.Join(*a.p, " ") }
+func (a *stringsArg) Type() string       { return "strings" }
+func (a *stringsArg) Set(v string) error { *a.p = append(*a.p, v); return nil }
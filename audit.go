@@ -0,0 +1,96 @@
+package psubcommands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// AuditRecord describes a single command invocation.
+type AuditRecord struct {
+	Time     time.Time         `json:"time"`
+	Command  string            `json:"command"`
+	Flags    map[string]string `json:"flags,omitempty"`
+	Args     []string          `json:"args,omitempty"`
+	Status   ExitStatus        `json:"status"`
+	Duration time.Duration     `json:"duration"`
+	User     string            `json:"user,omitempty"`
+}
+
+// AuditSink receives an AuditRecord for every executed command. Values of
+// secret flags are already masked, see MarkFlagSecret.
+type AuditSink interface {
+	Audit(ctx context.Context, record *AuditRecord) error
+}
+
+// AuditFunc is an AuditSink calling the function.
+type AuditFunc func(ctx context.Context, record *AuditRecord) error
+
+// Audit calls f.
+func (f AuditFunc) Audit(ctx context.Context, record *AuditRecord) error { return f(ctx, record) }
+
+// EnableAudit records every dispatched command invocation to sink,
+// including invocations rejected by validation, safe mode, Authorize or
+// a lock. Positional arguments are recorded as MaskedValue as they may hold
+// secrets. Failing to record an invocation is reported to Error but doesn't
+// change the ExitStatus of the command.
+func (c *Commander) EnableAudit(sink AuditSink) { c.audits = append(c.audits, sink) }
+
+// audit records the invocation of cmd, which started at start and returned
+// status, to the sinks registered with EnableAudit.
+func (c *Commander) audit(ctx context.Context, cmd Command, f *pflag.FlagSet, start time.Time, status ExitStatus) {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+
+	record := &AuditRecord{
+		Time:     start,
+		Command:  cmd.Name(),
+		Flags:    changedFlags(f),
+		Status:   status,
+		Duration: time.Since(start),
+		User:     user,
+	}
+	for range f.Args() {
+		record.Args = append(record.Args, MaskedValue)
+	}
+	for _, sink := range c.audits {
+		if err := sink.Audit(ctx, record); err != nil {
+			fmt.Fprintf(c.Error, c.tr("Failed to record audit log: %s\n"), err)
+		}
+	}
+}
+
+// EnableAudit records every command executed by the DefaultCommander to sink.
+func EnableAudit(sink AuditSink) { DefaultCommander.EnableAudit(sink) }
+
+type auditFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditFile returns an AuditSink appending every record as JSON line to
+// the file at path, which is created with mode 0600 if necessary.
+func NewAuditFile(path string) AuditSink { return &auditFile{path: path} }
+
+// Audit appends record to the file.
+func (a *auditFile) Audit(_ context.Context, record *AuditRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(record); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,32 @@
+//go:build !windows && !plan9
+
+package psubcommands
+
+import (
+	"context"
+	"encoding/json"
+	"log/syslog"
+)
+
+type auditSyslog struct {
+	w *syslog.Writer
+}
+
+// NewAuditSyslog returns an AuditSink sending every record as JSON to the
+// local syslog daemon with the specified tag and priority LOG_AUTHPRIV|LOG_NOTICE.
+func NewAuditSyslog(tag string) (AuditSink, error) {
+	w, err := syslog.New(syslog.LOG_AUTHPRIV|syslog.LOG_NOTICE, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &auditSyslog{w: w}, nil
+}
+
+// Audit sends record to syslog.
+func (a *auditSyslog) Audit(_ context.Context, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return a.w.Notice(string(data))
+}
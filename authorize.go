@@ -0,0 +1,33 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Requirer may be implemented by a Command to declare requirements like
+// "root" or "api-token" which must be met to execute it, see
+// Commander.Authorize.
+type Requirer interface {
+	Requires() []string
+}
+
+// authorize checks the requirements of cmd with the Authorize callback.
+func (c *Commander) authorize(ctx context.Context, cmd Command) ExitStatus {
+	r, ok := cmd.(Requirer)
+	if !ok || len(r.Requires()) == 0 {
+		return ExitSuccess
+	}
+
+	err := errors.New(c.tr("no authorizer configured"))
+	if c.Authorize != nil {
+		err = c.Authorize(ctx, cmd, r.Requires())
+	}
+	if err != nil {
+		c.debug(ctx, "permission denied", "command", cmd.Name(), "requires", r.Requires(), "error", err)
+		fmt.Fprintf(c.Error, c.tr("Subcommand %s: permission denied: %s\n"), cmd.Name(), err)
+		return ExitPermissionDenied
+	}
+	return ExitSuccess
+}
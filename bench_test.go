@@ -0,0 +1,64 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// benchCommand is a command doing nothing, with a single flag.
+type benchCommand string
+
+func (c benchCommand) Name() string     { return string(c) }
+func (c benchCommand) Synopsis() string { return "benchmark command " + string(c) }
+func (c benchCommand) Usage() string    { return string(c) + " [-v]\n" }
+
+func (c benchCommand) SetFlags(f *pflag.FlagSet) { f.BoolP("verbose", "v", false, "verbose output") }
+
+func (c benchCommand) Execute(context.Context, *pflag.FlagSet, ...interface{}) ExitStatus {
+	return ExitSuccess
+}
+
+// benchCommander returns a Commander with n commands in groups of 20.
+func benchCommander(n int) *Commander {
+	c := NewCommander("bench", nil, pflag.ContinueOnError)
+	c.Output, c.Error = io.Discard, io.Discard
+	for i := 0; i < n; i++ {
+		c.Register(fmt.Sprintf("group%d", i/20), benchCommand(fmt.Sprintf("cmd%d", i)))
+	}
+	return c
+}
+
+func BenchmarkExecute(b *testing.B) {
+	for _, n := range []int{400, 4000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			c := benchCommander(n)
+			argv := []string{fmt.Sprintf("cmd%d", n-1), "-v"}
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if status := c.ExecuteArgs(ctx, argv); status != ExitSuccess {
+					b.Fatalf("ExecuteArgs returned %d", status)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	for _, n := range []int{400, 4000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			c := benchCommander(n)
+			name := fmt.Sprintf("cmd%d", n-1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, ok := c.Lookup(name); !ok {
+					b.Fatalf("Lookup(%q) failed", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package psubcommands
+
+// GroupBuilder registers commands to a group using chained calls, e.g.
+//
+//	err := cdr.Group("remote").Add(add).Add(remove).Describe("manage remotes").Err()
+//
+// Commands are validated and registered immediately. After the first error
+// all further calls are no-ops and the error is returned by Err.
+type GroupBuilder struct {
+	cdr  *Commander
+	name string
+	err  error
+}
+
+// Group returns a GroupBuilder for the group with the specified name.
+func (c *Commander) Group(name string) *GroupBuilder {
+	return &GroupBuilder{cdr: c, name: name}
+}
+
+// Add registers cmd to the group like Commander.TryRegister. It fails with
+// a *CommandError if cmd has an empty name, a command with the same name is
+// already registered or its SetFlags panics.
+func (b *GroupBuilder) Add(cmd Command) *GroupBuilder {
+	if b.err == nil {
+		b.err = b.cdr.TryRegister(b.name, cmd)
+	}
+	return b
+}
+
+// Describe sets the description of the group, see Commander.SetGroupDescription.
+func (b *GroupBuilder) Describe(description string) *GroupBuilder {
+	if b.err == nil {
+		b.cdr.SetGroupDescription(b.name, description)
+	}
+	return b
+}
+
+// Hide hides the group from help output, see Commander.SetGroupHidden.
+func (b *GroupBuilder) Hide() *GroupBuilder {
+	if b.err == nil {
+		b.cdr.SetGroupHidden(b.name, true)
+	}
+	return b
+}
+
+// Err returns the first error encountered while building the group.
+func (b *GroupBuilder) Err() error { return b.err }
+
+// Group returns a GroupBuilder for a group of the DefaultCommander.
+func Group(name string) *GroupBuilder { return DefaultCommander.Group(name) }
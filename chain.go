@@ -0,0 +1,55 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+)
+
+// Chain executes the command lines in order, sharing ctx, and stops at the
+// first command not returning ExitSuccess. Each command line starts with the
+// subcommand name. Chain returns the ExitStatus of the last executed command.
+func (c *Commander) Chain(ctx context.Context, cmdlines [][]string, args ...interface{}) ExitStatus {
+	for i, argv := range cmdlines {
+		if len(argv) == 0 {
+			fmt.Fprintf(c.Error, c.tr("Missing subcommand in chain position %d\n"), i+1)
+			return ExitUsageError
+		}
+	}
+
+	status := ExitSuccess
+	for _, argv := range cmdlines {
+		if status = c.dispatch(ctx, argv, args...); status != ExitSuccess {
+			c.debug(ctx, "chain stopped", "command", argv[0], "status", int(status))
+			break
+		}
+	}
+	return status
+}
+
+// Chain executes the command lines on the DefaultCommander.
+func Chain(ctx context.Context, cmdlines [][]string, args ...interface{}) ExitStatus {
+	return DefaultCommander.Chain(ctx, cmdlines, args...)
+}
+
+// splitChain splits argv at every ChainSeparator. Separators after "--"
+// belong to the command line of the preceding command.
+func (c *Commander) splitChain(argv []string) [][]string {
+	if c.ChainSeparator == "" {
+		return [][]string{argv}
+	}
+
+	cmdlines := [][]string{}
+	current := []string{}
+	passThrough := false
+	for _, arg := range argv {
+		switch {
+		case arg == c.ChainSeparator && !passThrough:
+			cmdlines = append(cmdlines, current)
+			current = []string{}
+		default:
+			passThrough = passThrough || arg == "--"
+			current = append(current, arg)
+		}
+	}
+	return append(cmdlines, current)
+}
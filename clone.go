@@ -0,0 +1,322 @@
+package psubcommands
+
+import (
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Clone returns a copy of c with the same commands, groups and settings
+// and a new, unparsed top-level FlagSet, so the copy can be executed even
+// if c already was. Built-in commands like help are bound to the copy.
+//
+// The top-level flags of the copy get new values, set to their defaults,
+// so variables bound to the flags of c aren't updated by the copy. Values
+// of custom types which can't be re-created are shared with c.
+func (c *Commander) Clone() *Commander {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clone := &Commander{
+		onError:     c.onError,
+		ownFlags:    true,
+		name:        c.name,
+		provided:    append([]interface{}{}, c.provided...),
+		topics:      append([]*helpTopic{}, c.topics...),
+		annotations: map[string]map[string]string{},
+		atExit:      append([]func(){}, c.atExit...),
+		onShutdown:  append(c.onShutdown[:0:0], c.onShutdown...),
+		onStart:     append(c.onStart[:0:0], c.onStart...),
+		onEnd:       append(c.onEnd[:0:0], c.onEnd...),
+		audits:      append(c.audits[:0:0], c.audits...),
+		middlewares: append([]Middleware{}, c.middlewares...),
+		verbosity:   c.verbosity,
+		aliases:     copyMap(c.aliases),
+		aliasFile:   c.aliasFile,
+		redirects:   copyMap(c.redirects),
+		exitCodes:   copyMap(c.exitCodes),
+		statsFile:   c.statsFile,
+		rcFile:      c.rcFile,
+		normalize:   c.normalize,
+		fallback:    c.fallback,
+
+		Output:                  c.Output,
+		Error:                   c.Error,
+		Input:                   c.Input,
+		PromptMissing:           c.PromptMissing,
+		Timeout:                 c.Timeout,
+		GroupLess:               c.GroupLess,
+		CommandLess:             c.CommandLess,
+		PinnedGroups:            append([]string{}, c.PinnedGroups...),
+		PrefixMatching:          c.PrefixMatching,
+		Translate:               c.Translate,
+		ExternalCommands:        c.ExternalCommands,
+		InterspersedGlobalFlags: c.InterspersedGlobalFlags,
+		Pager:                   c.Pager,
+		Logger:                  c.Logger,
+		ArgumentFiles:           c.ArgumentFiles,
+		InteractivePicker:       c.InteractivePicker,
+		HistoryFile:             c.HistoryFile,
+		ContinueOnScriptError:   c.ContinueOnScriptError,
+		ShutdownTimeout:         c.ShutdownTimeout,
+		FlushTimeout:            c.FlushTimeout,
+		Version:                 c.Version,
+		Revision:                c.Revision,
+		BuildTime:               c.BuildTime,
+		VersionInHelp:           c.VersionInHelp,
+		CompactHelp:             c.CompactHelp,
+		CacheHelp:               c.CacheHelp,
+		HelpPageSize:            c.HelpPageSize,
+		FlagOrder:               c.FlagOrder,
+		GroupShorthandFlags:     c.GroupShorthandFlags,
+		License:                 c.License,
+		Notices:                 c.Notices,
+		ShellAliases:            copyMap(c.ShellAliases),
+		ShellInit:               copyMap(c.ShellInit),
+		NamespaceSeparator:      c.NamespaceSeparator,
+		ExperimentalEnv:         c.ExperimentalEnv,
+		NoInputEnv:              c.NoInputEnv,
+		Authorize:               c.Authorize,
+		Retry:                   c.Retry,
+		ChainSeparator:          c.ChainSeparator,
+		WorkDir:                 c.WorkDir,
+		Env:                     copyMap(c.Env),
+		LookupEnv:               c.LookupEnv,
+		MapContextErrors:        c.MapContextErrors,
+		SafeMode:                c.SafeMode,
+		ForceEnv:                c.ForceEnv,
+	}
+	for k, v := range c.annotations {
+		clone.annotations[k] = copyMap(v)
+	}
+	for command, flags := range c.deprecated {
+		if clone.deprecated == nil {
+			clone.deprecated = map[string]map[string]string{}
+		}
+		clone.deprecated[command] = copyMap(flags)
+	}
+	for command, presets := range c.presets {
+		if clone.presets == nil {
+			clone.presets = map[string]map[string]map[string]string{}
+		}
+		clone.presets[command] = map[string]map[string]string{}
+		for name, values := range presets {
+			clone.presets[command][name] = copyMap(values)
+		}
+	}
+	// A nil rc makes the clone load the rc file itself.
+	c.rcMu.Lock()
+	if c.rc != nil {
+		clone.rc = map[string]map[string]string{}
+		for k, v := range c.rc {
+			clone.rc[k] = copyMap(v)
+		}
+	}
+	c.rcMu.Unlock()
+
+	for _, g := range c.commands {
+		group := *g
+		group.commands = make([]Command, len(g.commands))
+		for i, cmd := range g.commands {
+			group.commands[i] = c.rebind(cmd, clone)
+		}
+		clone.commands = append(clone.commands, &group)
+	}
+
+	clone.topFlags = c.freshFlagSet(false)
+	clone.topFlags.Usage = clone.usage
+	return clone
+}
+
+// rebind returns cmd bound to clone if cmd is a built-in command of c.
+func (c *Commander) rebind(cmd Command, clone *Commander) Command {
+	v := reflect.ValueOf(cmd)
+	commander := reflect.TypeOf(c)
+	if v.Kind() != reflect.Ptr || !v.Type().ConvertibleTo(commander) {
+		return cmd
+	}
+	if v.Convert(commander).Interface().(*Commander) != c {
+		return cmd
+	}
+	return reflect.ValueOf(clone).Convert(v.Type()).Interface().(Command)
+}
+
+// Reset prepares c to be executed again by replacing its top-level FlagSet
+// with an unparsed one. The values of the top-level flags are reset to
+// their defaults. Reset is only needed if the FlagSet was parsed by the
+// user, Execute and ExecuteArgs parse the top-level flags on every call.
+func (c *Commander) Reset() {
+	f := c.freshFlagSet(true)
+	f.Usage = c.usage
+	c.topFlags = f
+	c.ownFlags = true
+	c.parsedArgs = false
+}
+
+// Reset prepares the DefaultCommander to be executed again.
+func Reset() { DefaultCommander.Reset() }
+
+// freshFlagSet returns an unparsed FlagSet with the top-level flags of c,
+// set to their default values. If shared is true the flags keep their
+// values, which are reset, otherwise they get new values and the flags of
+// c are left untouched.
+func (c *Commander) freshFlagSet(shared bool) *pflag.FlagSet {
+	old := c.topFlags
+	f := pflag.NewFlagSet(old.Name(), c.onError)
+	f.SortFlags = old.SortFlags
+	f.SetOutput(old.Output())
+	if c.normalize != nil {
+		f.SetNormalizeFunc(c.normalize)
+	}
+
+	// Aliases like --assume-yes share the value of another flag.
+	values := map[pflag.Value]pflag.Value{}
+	old.VisitAll(func(flag *pflag.Flag) {
+		cp := *flag
+		comparable := reflect.TypeOf(flag.Value).Comparable()
+		switch {
+		case shared:
+			resetFlag(flag)
+			cp.Changed = false
+		case comparable && values[flag.Value] != nil:
+			cp.Value, cp.Changed = values[flag.Value], false
+		default:
+			cp.Value, cp.Changed = newValue(flag.Value), false
+			if comparable && cp.Value != flag.Value {
+				resetFlag(&cp)
+			}
+			if comparable {
+				values[flag.Value] = cp.Value
+			}
+		}
+		f.AddFlag(&cp)
+	})
+	return f
+}
+
+// newValue returns a new Value of the same type as v. Values of custom types
+// are only re-created if they are pointers to basic types, others are shared.
+func newValue(v pflag.Value) pflag.Value {
+	if w, ok := v.(*validatedValue); ok {
+		return &validatedValue{Value: newValue(w.Value), validators: w.validators}
+	}
+	if nv := newPflagValue(v.Type()); nv != nil && reflect.TypeOf(nv) == reflect.TypeOf(v) {
+		return nv
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() == reflect.Struct {
+		return v
+	}
+	return reflect.New(t.Elem()).Interface().(pflag.Value)
+}
+
+// newPflagValue returns a new Value of the pflag type named typ, or nil for
+// unknown types.
+func newPflagValue(typ string) pflag.Value {
+	f := pflag.NewFlagSet("", pflag.ContinueOnError)
+	switch typ {
+	case "bool":
+		f.Bool("v", false, "")
+	case "boolSlice":
+		f.BoolSlice("v", nil, "")
+	case "bytesBase64":
+		f.BytesBase64("v", nil, "")
+	case "bytesHex":
+		f.BytesHex("v", nil, "")
+	case "count":
+		f.Count("v", "")
+	case "duration":
+		f.Duration("v", 0, "")
+	case "durationSlice":
+		f.DurationSlice("v", nil, "")
+	case "float32":
+		f.Float32("v", 0, "")
+	case "float32Slice":
+		f.Float32Slice("v", nil, "")
+	case "float64":
+		f.Float64("v", 0, "")
+	case "float64Slice":
+		f.Float64Slice("v", nil, "")
+	case "int":
+		f.Int("v", 0, "")
+	case "int8":
+		f.Int8("v", 0, "")
+	case "int16":
+		f.Int16("v", 0, "")
+	case "int32":
+		f.Int32("v", 0, "")
+	case "int32Slice":
+		f.Int32Slice("v", nil, "")
+	case "int64":
+		f.Int64("v", 0, "")
+	case "int64Slice":
+		f.Int64Slice("v", nil, "")
+	case "intSlice":
+		f.IntSlice("v", nil, "")
+	case "ip":
+		f.IP("v", nil, "")
+	case "ipSlice":
+		f.IPSlice("v", nil, "")
+	case "ipMask":
+		f.IPMask("v", nil, "")
+	case "ipNet":
+		f.IPNet("v", net.IPNet{}, "")
+	case "ipNetSlice":
+		f.IPNetSlice("v", nil, "")
+	case "string":
+		f.String("v", "", "")
+	case "stringArray":
+		f.StringArray("v", nil, "")
+	case "stringSlice":
+		f.StringSlice("v", nil, "")
+	case "stringToInt":
+		f.StringToInt("v", nil, "")
+	case "stringToInt64":
+		f.StringToInt64("v", nil, "")
+	case "stringToString":
+		f.StringToString("v", nil, "")
+	case "uint":
+		f.Uint("v", 0, "")
+	case "uint8":
+		f.Uint8("v", 0, "")
+	case "uint16":
+		f.Uint16("v", 0, "")
+	case "uint32":
+		f.Uint32("v", 0, "")
+	case "uint64":
+		f.Uint64("v", 0, "")
+	case "uintSlice":
+		f.UintSlice("v", nil, "")
+	default:
+		return nil
+	}
+	return f.Lookup("v").Value
+}
+
+// resetFlag sets flag back to its default value.
+func resetFlag(flag *pflag.Flag) {
+	flag.Changed = false
+	if s, ok := unwrapValue(flag.Value).(pflag.SliceValue); ok {
+		values := []string{}
+		if def := strings.Trim(flag.DefValue, "[]"); def != "" {
+			values = strings.Split(def, ",")
+		}
+		s.Replace(values)
+		return
+	}
+	_ = flag.Value.Set(flag.DefValue)
+}
+
+// copyMap returns a copy of m.
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[K]V, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
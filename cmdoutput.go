@@ -0,0 +1,28 @@
+package psubcommands
+
+import "io"
+
+// OutputRouter may be implemented by a Command to route its help and its
+// parse and usage errors to other writers than Commander.Output and
+// Commander.Error, e.g. to keep the standard output of a command clean for
+// machine consumption. A nil writer keeps the writer of the Commander.
+type OutputRouter interface {
+	HelpOutput() io.Writer
+	ErrorOutput() io.Writer
+}
+
+// helpOutput returns the writer for the help of cmd.
+func (c *Commander) helpOutput(cmd Command) io.Writer {
+	if r, ok := unwrap(cmd).(OutputRouter); ok && r.HelpOutput() != nil {
+		return r.HelpOutput()
+	}
+	return c.Output
+}
+
+// errorOutput returns the writer for parse and usage errors of cmd.
+func (c *Commander) errorOutput(cmd Command) io.Writer {
+	if r, ok := unwrap(cmd).(OutputRouter); ok && r.ErrorOutput() != nil {
+		return r.ErrorOutput()
+	}
+	return c.Error
+}
@@ -0,0 +1,540 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Completer may be implemented by a Command to provide completion candidates
+// for the values of its flags and its positional arguments. name is the name
+// of the flag whose value is completed or empty for positional arguments.
+type Completer interface {
+	Complete(name, toComplete string) []string
+}
+
+// completionHint tells the completion scripts to fall back to native
+// file or directory completion.
+type completionHint struct {
+	kind       string
+	extensions []string
+}
+
+// completionDirective is a bit set telling the completion scripts how to
+// treat the candidates. The values are compatible with cobra, so its
+// completion scripts work with the "__complete" command as well.
+type completionDirective int
+
+const (
+	directiveError completionDirective = 1 << iota
+	directiveNoSpace
+	directiveNoFileComp
+	directiveFilterFileExt
+	directiveFilterDirs
+)
+
+// directive returns the directive of h. Without a hint the shell must not
+// complete files.
+func (h completionHint) directive() completionDirective {
+	switch {
+	case h.kind == "dir":
+		return directiveFilterDirs
+	case h.kind == "file" && len(h.extensions) > 0:
+		return directiveFilterFileExt
+	case h.kind == "file":
+		return 0
+	}
+	return directiveNoFileComp
+}
+
+// flagHint returns the completion hint of flag.
+func flagHint(flag *pflag.Flag) completionHint {
+	if exts, ok := flag.Annotations[annotationFilename]; ok {
+		return completionHint{kind: "file", extensions: exts}
+	}
+	if hasAnnotation(flag, annotationDirname) {
+		return completionHint{kind: "dir"}
+	}
+	return completionHint{}
+}
+
+// argHint returns the completion hint for the positional arguments of cmd.
+func argHint(cmd Command) completionHint {
+	spec, _ := argSpec(cmd)
+	switch spec.Completion {
+	case CompleteFiles:
+		return completionHint{kind: "file", extensions: trimDots(spec.Extensions)}
+	case CompleteDirs:
+		return completionHint{kind: "dir"}
+	}
+	return completionHint{}
+}
+
+func trimDots(extensions []string) []string {
+	trimmed := make([]string, len(extensions))
+	for i, ext := range extensions {
+		trimmed[i] = strings.TrimPrefix(ext, ".")
+	}
+	return trimmed
+}
+
+// completionGroup holds the hidden commands of the completion subsystem.
+const completionGroup = "__completion"
+
+// RegisterCompletionCommand registers the "completion" command printing shell
+// completion scripts to the specified group. The scripts delegate to the
+// hidden "__complete" command, which is registered as well.
+func (c *Commander) RegisterCompletionCommand(group string) {
+	c.Register(group, (*completionCommand)(c))
+	c.registerComplete()
+}
+
+// registerComplete registers the hidden "__complete" command unless it
+// is registered already.
+func (c *Commander) registerComplete() {
+	if _, ok := c.Lookup("__complete"); ok {
+		return
+	}
+	c.Register(completionGroup, (*completeCommand)(c), (*completeNoDescCommand)(c))
+	c.SetGroupHidden(completionGroup, true)
+}
+
+// RegisterCompletionCommand registers the completion commands on the DefaultCommander.
+func RegisterCompletionCommand(group string) { DefaultCommander.RegisterCompletionCommand(group) }
+
+// complete returns the completion candidates for the last element of args
+// and a hint whether the shell should additionally complete files.
+// args contains the command line without the program name.
+func (c *Commander) complete(args []string) ([]string, completionHint) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	toComplete := args[len(args)-1]
+	words := args[:len(args)-1]
+
+	// Only the last command line of a chain is completed, it starts with
+	// the subcommand. Otherwise skip leading top-level flags to find it.
+	i := 0
+	if cmdlines := c.splitChain(words); len(cmdlines) > 1 {
+		words = cmdlines[len(cmdlines)-1]
+	} else {
+		i = skipFlags(c.topFlags, words)
+	}
+	if i >= len(words) {
+		if strings.HasPrefix(toComplete, "-") {
+			return completeFlagNames(c.topFlags, toComplete), completionHint{}
+		}
+		if flag := valueFlag(c.topFlags, words); flag != nil {
+			return nil, flagHint(flag)
+		}
+		return c.completeCommandNames(toComplete), completionHint{}
+	}
+
+	cmd, ok := c.Lookup(words[i])
+	if !ok {
+		return nil, completionHint{}
+	}
+	cmd = unwrap(cmd)
+	f := c.commandFlags(cmd)
+	words = words[i+1:]
+
+	completer, _ := cmd.(Completer)
+	complete := func(name, toComplete string) []string {
+		if flag := f.Lookup(name); name != "" && flag != nil {
+			if values, ok := flag.Annotations[annotationEnum]; ok {
+				return filterPrefix(values, toComplete)
+			}
+		}
+		if completer == nil {
+			return nil
+		}
+		return filterPrefix(completer.Complete(name, toComplete), toComplete)
+	}
+
+	if strings.HasPrefix(toComplete, "--") && strings.Contains(toComplete, "=") {
+		parts := strings.SplitN(toComplete[2:], "=", 2)
+		candidates := []string{}
+		for _, v := range complete(parts[0], parts[1]) {
+			candidates = append(candidates, fmt.Sprintf("--%s=%s", parts[0], v))
+		}
+		hint := completionHint{}
+		if flag := f.Lookup(parts[0]); flag != nil {
+			hint = flagHint(flag)
+		}
+		return candidates, hint
+	}
+	if strings.HasPrefix(toComplete, "-") {
+		return completeFlagNames(f, toComplete), completionHint{}
+	}
+	if flag := valueFlag(f, words); flag != nil {
+		return complete(flag.Name, toComplete), flagHint(flag)
+	}
+	return complete("", toComplete), argHint(cmd)
+}
+
+// completeCommandNames returns all visible command names starting with prefix.
+func (c *Commander) completeCommandNames(prefix string) []string {
+	candidates := []string{}
+	for _, g := range c.orderedGroups() {
+		for _, cmd := range g.commands {
+			candidates = append(candidates, cmd.Name())
+		}
+	}
+	return c.collapseNamespaces(filterPrefix(candidates, prefix), prefix)
+}
+
+// skipFlags returns the index of the first positional argument in words.
+func skipFlags(f *pflag.FlagSet, words []string) int {
+	for i := 0; i < len(words); i++ {
+		if words[i] == "--" {
+			return i + 1
+		}
+		if !strings.HasPrefix(words[i], "-") {
+			return i
+		}
+		if valueFlag(f, words[:i+1]) != nil {
+			i++
+		}
+	}
+	return len(words)
+}
+
+// valueFlag returns the flag if the last element of words is a flag expecting
+// a value in the next argument.
+func valueFlag(f *pflag.FlagSet, words []string) *pflag.Flag {
+	if len(words) == 0 {
+		return nil
+	}
+	last := words[len(words)-1]
+	if strings.Contains(last, "=") || !strings.HasPrefix(last, "-") || last == "--" {
+		return nil
+	}
+
+	var flag *pflag.Flag
+	if strings.HasPrefix(last, "--") {
+		flag = f.Lookup(last[2:])
+	} else {
+		flag = f.ShorthandLookup(last[len(last)-1:])
+	}
+	if flag == nil || flag.NoOptDefVal != "" {
+		return nil
+	}
+	return flag
+}
+
+// completeFlagNames returns all visible flags of f starting with prefix.
+func completeFlagNames(f *pflag.FlagSet, prefix string) []string {
+	candidates := []string{}
+	f.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		candidates = append(candidates, "--"+flag.Name)
+	})
+	return filterPrefix(candidates, prefix)
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	filtered := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+type completeCommand Commander
+
+// Name of this command.
+func (*completeCommand) Name() string { return "__complete" }
+
+// Synopsis returns a short description of this command.
+func (*completeCommand) Synopsis() string { return "print completion candidates" }
+
+// SetFlags adds the flags to the FlagSet.
+func (*completeCommand) SetFlags(f *pflag.FlagSet) { f.SetInterspersed(false) }
+
+// rawArgs makes the Commander pass the partial command line unparsed.
+func (*completeCommand) rawArgs() {}
+
+// Execute executs this command and returns it's ExitStatus.
+func (c *completeCommand) Execute(_ context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	(*Commander)(c).writeCompletions(f.Args(), true)
+	return ExitSuccess
+}
+
+type completeNoDescCommand Commander
+
+// Name of this command.
+func (*completeNoDescCommand) Name() string { return "__completeNoDesc" }
+
+// Synopsis returns a short description of this command.
+func (*completeNoDescCommand) Synopsis() string {
+	return "print completion candidates without descriptions"
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*completeNoDescCommand) SetFlags(f *pflag.FlagSet) { f.SetInterspersed(false) }
+
+// rawArgs makes the Commander pass the partial command line unparsed.
+func (*completeNoDescCommand) rawArgs() {}
+
+// Execute executs this command and returns it's ExitStatus.
+func (c *completeNoDescCommand) Execute(_ context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	(*Commander)(c).writeCompletions(f.Args(), false)
+	return ExitSuccess
+}
+
+// rawArgser is implemented by commands receiving their arguments without
+// flag parsing.
+type rawArgser interface{ rawArgs() }
+
+// writeCompletions writes the candidates completing the last element of
+// args to Output, one per line and optionally followed by a tab and a
+// description, and a final line with the directive, e.g. ":4". For file
+// completion restricted to extensions the extensions are written instead
+// of the candidates.
+func (c *Commander) writeCompletions(args []string, descriptions bool) {
+	// Scripts of older versions separated the command line with "--".
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	candidates, hint := c.complete(args)
+	directive := hint.directive()
+	switch {
+	case directive == directiveFilterFileExt:
+		candidates = hint.extensions
+	case len(candidates) == 1 && c.NamespaceSeparator != "" && strings.HasSuffix(candidates[0], c.NamespaceSeparator):
+		directive |= directiveNoSpace
+	}
+
+	for _, candidate := range candidates {
+		if descriptions && directive != directiveFilterFileExt {
+			if description := c.describeCandidate(args, candidate); description != "" {
+				candidate += "\t" + description
+			}
+		}
+		fmt.Fprintln(c.Output, candidate)
+	}
+	fmt.Fprintf(c.Output, ":%d\n", directive)
+}
+
+// describeCandidate returns the description of a completion candidate for
+// the command line args: the usage of flags and the synopsis of commands.
+func (c *Commander) describeCandidate(args []string, candidate string) string {
+	if len(args) == 0 || strings.Contains(candidate, "\t") {
+		return ""
+	}
+	words := args[:len(args)-1]
+	i := skipFlags(c.topFlags, words)
+
+	var f *pflag.FlagSet
+	if i < len(words) {
+		cmd, ok := c.Lookup(words[i])
+		if !ok {
+			return ""
+		}
+		f = c.commandFlags(unwrap(cmd))
+	} else {
+		f = c.topFlags
+	}
+
+	switch {
+	case strings.HasPrefix(candidate, "--") && !strings.Contains(candidate, "="):
+		if flag := f.Lookup(candidate[2:]); flag != nil {
+			return firstLine(flag.Usage)
+		}
+	case i >= len(words):
+		if cmd, ok := c.Lookup(candidate); ok {
+			return firstLine(c.synopsis(cmd))
+		}
+	}
+	return ""
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+type completionCommand Commander
+
+// Name of this command.
+func (*completionCommand) Name() string { return "completion" }
+
+// Synopsis returns a short description of this command.
+func (c *completionCommand) Synopsis() string {
+	return (*Commander)(c).tr("print shell completion script for bash, zsh or fish")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*completionCommand) SetFlags(*pflag.FlagSet) {}
+
+// Args returns the positional arguments of this command.
+func (*completionCommand) Args() ArgSpec {
+	return ArgSpec{Names: []string{"shell"}, Min: 1, Max: 1}
+}
+
+// Complete returns the supported shells.
+func (*completionCommand) Complete(name, _ string) []string {
+	if name != "" {
+		return nil
+	}
+	return []string{"bash", "zsh", "fish"}
+}
+
+// Execute executs this command and returns it's ExitStatus.
+func (c *completionCommand) Execute(_ context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	if err := (*Commander)(c).WriteCompletionScript(c.Output, f.Arg(0)); err != nil {
+		return UsageErrorf(f, "%s", err)
+	}
+	return ExitSuccess
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// WriteCompletionScript writes the completion script for shell ("bash", "zsh"
+// or "fish") to w. The script requires the completion commands to be
+// registered with RegisterCompletionCommand.
+func (c *Commander) WriteCompletionScript(w io.Writer, shell string) error {
+	name := filepath.Base(c.name)
+	fn := "_" + nonIdentifier.ReplaceAllString(name, "_") + "_complete"
+
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf(c.tr("unsupported shell %q"), shell)
+	}
+
+	_, err := io.WriteString(w, strings.NewReplacer("{{name}}", name, "{{fn}}", fn).Replace(script))
+	return err
+}
+
+const bashCompletion = `# bash completion for {{name}}
+{{fn}}() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -r -a words <<< "$line"
+    [[ "$line" == *" " ]] && words+=("")
+
+    local IFS=$'\n' directive=0
+    COMPREPLY=($("${words[0]}" __completeNoDesc "${words[@]:1}" 2>/dev/null))
+    if [[ ${#COMPREPLY[@]} -gt 0 && "${COMPREPLY[-1]}" == :* ]]; then
+        directive="${COMPREPLY[-1]#:}"
+        unset 'COMPREPLY[-1]'
+    fi
+    (( directive & 1 )) && { COMPREPLY=(); return; }
+
+    local cur="${words[-1]}"
+    if (( directive & 8 )); then
+        local -a exts=("${COMPREPLY[@]}")
+        compopt -o filenames
+        COMPREPLY=($(compgen -d -- "$cur"))
+        local ext
+        for ext in "${exts[@]}"; do
+            COMPREPLY+=($(compgen -f -X "!*.$ext" -- "$cur"))
+        done
+        return
+    fi
+    if (( directive & 16 )); then
+        compopt -o filenames
+        COMPREPLY=($(compgen -d -- "$cur"))
+        return
+    fi
+
+    if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+        cur="${cur#*=}"
+        COMPREPLY=("${COMPREPLY[@]#*=}")
+    fi
+    (( directive & 2 )) && compopt -o nospace
+    if [[ "$cur" == *:* && "$COMP_WORDBREAKS" == *:* ]]; then
+        local colon="${cur%"${cur##*:}"}"
+        COMPREPLY=("${COMPREPLY[@]#"$colon"}")
+    fi
+    if (( ! (directive & 4) )); then
+        compopt -o filenames
+        COMPREPLY+=($(compgen -f -- "$cur"))
+    fi
+}
+complete -F {{fn}} {{name}}
+`
+
+const zshCompletion = `#compdef {{name}}
+{{fn}}() {
+    local -a out values displays
+    out=("${(@f)$(${words[1]} __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    local directive=0
+    if [[ "${out[-1]}" == :* ]]; then
+        directive="${out[-1]#:}"
+        out=("${(@)out[1,-2]}")
+    fi
+    (( directive & 1 )) && return 1
+    if (( directive & 8 )); then
+        _files -g "*.(${(j:|:)out})"
+        return
+    fi
+    if (( directive & 16 )); then
+        _files -/
+        return
+    fi
+
+    local item
+    for item in ${out:#}; do
+        values+=("${item%%$'\t'*}")
+        if [[ "$item" == *$'\t'* ]]; then
+            displays+=("${item%%$'\t'*}  -- ${item#*$'\t'}")
+        else
+            displays+=("$item")
+        fi
+    done
+    local -a suffix
+    (( directive & 2 )) && suffix=(-S '')
+    [[ "${words[CURRENT]}" == --*=* ]] && compset -P '*='
+    compadd -l -d displays "${suffix[@]}" -- "${values[@]}"
+    (( directive & 4 )) || _files
+}
+compdef {{fn}} {{name}}
+`
+
+const fishCompletion = `# fish completion for {{name}}
+function {{fn}}
+    set -l out ({{name}} __complete (commandline -opc)[2..-1] (commandline -ct))
+    set -l directive 0
+    if test (count $out) -gt 0; and string match -q ':*' -- $out[-1]
+        set directive (string sub -s 2 -- $out[-1])
+        set -e out[-1]
+    end
+    if test (math "bitand($directive, 1)") -ne 0
+        return
+    end
+    if test (math "bitand($directive, 8)") -ne 0
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    if test (math "bitand($directive, 16)") -ne 0
+        __fish_complete_directories (commandline -ct)
+        return
+    end
+    printf '%s\n' $out
+    if test (math "bitand($directive, 4)") -eq 0
+        __fish_complete_path (commandline -ct)
+    end
+end
+complete -c {{name}} -f -a '({{fn}})'
+`
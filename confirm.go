@@ -0,0 +1,62 @@
+package psubcommands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// YesFlag is the name of the flag registered by EnableYesFlag to
+	// answer all confirmations with yes.
+	YesFlag = "yes"
+	// AssumeYesFlag is a hidden alias of YesFlag.
+	AssumeYesFlag = "assume-yes"
+)
+
+// ErrNotInteractive is returned by Confirm if the command isn't Interactive
+// and the confirmation wasn't given with --yes.
+var ErrNotInteractive = errors.New("psubcommands: confirmation required but input is not interactive")
+
+// EnableYesFlag registers the top-level flag -y/--yes, with the hidden alias
+// --assume-yes, which makes Confirm succeed without asking.
+func (c *Commander) EnableYesFlag() {
+	yes := c.topFlags.BoolP(YesFlag, "y", false, c.tr("answer all confirmations with yes"))
+	c.topFlags.BoolVar(yes, AssumeYesFlag, false, c.tr("alias for --yes"))
+	c.topFlags.MarkHidden(AssumeYesFlag)
+}
+
+// EnableYesFlag registers the --yes flag on the DefaultCommander.
+func EnableYesFlag() { DefaultCommander.EnableYesFlag() }
+
+// Confirm asks the user to confirm message with yes or no and reports the
+// answer. It returns true without asking if --yes was given, see
+// EnableYesFlag. If the current command isn't Interactive Confirm returns
+// ErrNotInteractive instead of assuming an answer.
+func Confirm(ctx context.Context, message string) (bool, error) {
+	tr := identity
+	if c := CommanderFromContext(ctx); c != nil {
+		if yes, _ := c.topFlags.GetBool(YesFlag); yes {
+			return true, nil
+		}
+		tr = c.tr
+	}
+
+	if !Interactive(ctx) {
+		return false, ErrNotInteractive
+	}
+	streams := Streams(ctx)
+
+	fmt.Fprintf(streams.Out, tr("%s [y/N]: "), message)
+	answer, err := bufio.NewReader(streams.In).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package psubcommands
+
+import "io"
+
+// enableVirtualTerminal reports whether the terminal w understands ANSI
+// escape sequences, which all supported terminals do.
+func enableVirtualTerminal(io.Writer) bool { return true }
+
+// exitCode returns the process exit code for status. POSIX shells only see
+// the low 8 bits of the code, so statuses outside 0-255 are reported as
+// ExitFailure instead of being truncated, possibly to 0.
+func exitCode(status ExitStatus) int {
+	if status < 0 || status > 255 {
+		return int(ExitFailure)
+	}
+	return int(status)
+}
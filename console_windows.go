@@ -0,0 +1,34 @@
+//go:build windows
+
+package psubcommands
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminal enables processing of ANSI escape sequences on the
+// console w and reports whether w understands them. Consoles before
+// Windows 10 don't support them.
+func enableVirtualTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	h := windows.Handle(file.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
+
+// exitCode returns the process exit code for status. Exit codes are 32 bit
+// on Windows and both cmd's %ERRORLEVEL% and PowerShell's $LASTEXITCODE
+// show them as signed integers, so status is passed unchanged.
+func exitCode(status ExitStatus) int { return int(int32(status)) }
@@ -0,0 +1,63 @@
+package psubcommands
+
+import (
+	"context"
+
+	"github.com/spf13/pflag"
+)
+
+type contextKey int
+
+const (
+	providedKey contextKey = iota
+	streamsKey
+	commanderKey
+	unknownFlagsKey
+	dryRunKey
+	verbosityKey
+	outputFormatKey
+	invocationStreamsKey
+	envKey
+	workdirKey
+	resultKey
+	processLockKey
+)
+
+// withContext returns ctx enriched with everything the Commander hands to
+// its subcommands.
+func (c *Commander) withContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, commanderKey, c)
+	streams, ok := ctx.Value(invocationStreamsKey).(*IOStreams)
+	if !ok {
+		streams = c.IOStreams()
+	}
+	ctx = context.WithValue(ctx, streamsKey, streams)
+	ctx = context.WithValue(ctx, workdirKey, &tempWorkdir{c: c})
+	if _, ok := ctx.Value(envKey).(EnvFunc); !ok && c.LookupEnv != nil {
+		ctx = WithEnv(ctx, c.LookupEnv)
+	}
+	if len(c.provided) > 0 {
+		ctx = context.WithValue(ctx, providedKey, c.provided)
+	}
+	return ctx
+}
+
+// CommanderFromContext returns the Commander executing the current command
+// or nil if ctx wasn't passed by a Commander. The top-level flags are
+// available through its FlagSet method.
+func CommanderFromContext(ctx context.Context) *Commander {
+	c, _ := ctx.Value(commanderKey).(*Commander)
+	return c
+}
+
+// GlobalFlags returns the parsed top-level flags of the Commander executing
+// the current command, e.g. to read a --config flag defined on them. With
+// InterspersedGlobalFlags the values include global flags given after the
+// subcommand name. GlobalFlags returns an empty FlagSet if ctx wasn't passed
+// by a Commander.
+func GlobalFlags(ctx context.Context) *pflag.FlagSet {
+	if c := CommanderFromContext(ctx); c != nil {
+		return c.topFlags
+	}
+	return pflag.NewFlagSet("", pflag.ContinueOnError)
+}
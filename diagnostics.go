@@ -0,0 +1,51 @@
+package psubcommands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+)
+
+// misplacedFlagHint returns a hint if err reports an unknown flag which is
+// a top-level flag. Other commands' flags aren't inspected, as creating
+// their FlagSets is too expensive for programs with many commands.
+func (c *Commander) misplacedFlagHint(cmd Command, err error) string {
+	var notExist *pflag.NotExistError
+	if !errors.As(err, &notExist) || notExist.GetSpecifiedName() == "" {
+		return ""
+	}
+	name := notExist.GetSpecifiedName()
+	if c.topFlags.Lookup(name) == nil {
+		return ""
+	}
+	tool := filepath.Base(c.name)
+	return fmt.Sprintf(c.tr("--%s is a global flag; did you mean `%s --%s %s ...`?"), name, tool, name, cmd.Name())
+}
+
+// usageLine returns the one-line usage of cmd, or of the Commander if cmd is nil.
+func (c *Commander) usageLine(cmd Command) string {
+	if cmd == nil {
+		return fmt.Sprintf(c.tr("Usage: %s <flags> <subcommand> <subcommand args>"), c.name)
+	}
+	return fmt.Sprintf(c.tr("Usage: %s <flags> %s <subcommand flags>%s"), c.name, cmd.Name(), argsUsage(cmd))
+}
+
+// usageHint returns the usage line of cmd, or of the Commander if cmd is nil,
+// followed by directions where to find the full help.
+func (c *Commander) usageHint(cmd Command) string {
+	tool := filepath.Base(c.name)
+	var details string
+	switch _, hasHelp := c.Lookup("help"); {
+	case cmd == nil && hasHelp:
+		details = fmt.Sprintf(c.tr("Run '%s help' for details."), tool)
+	case cmd == nil:
+		details = fmt.Sprintf(c.tr("Run '%s --help' for details."), tool)
+	case hasHelp:
+		details = fmt.Sprintf(c.tr("Run '%s help %s' for details."), tool, cmd.Name())
+	default:
+		details = fmt.Sprintf(c.tr("Run '%s %s --help' for details."), tool, cmd.Name())
+	}
+	return c.usageLine(cmd) + "\n" + details + "\n"
+}
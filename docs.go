@@ -0,0 +1,390 @@
+package psubcommands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// docPage is the format independent content of a single documentation page.
+type docPage struct {
+	title       string
+	synopsis    string
+	usage       string
+	description string
+	examples    string
+	flags       []*FlagSpec
+	commands    []*CommandSpec
+	seeAlso     []string
+}
+
+// docFormat renders a docPage into a file with the given extension.
+type docFormat struct {
+	ext    string
+	render func(w io.Writer, p *docPage)
+}
+
+var docFormats = map[string]docFormat{
+	"man":      {ext: ".1", render: renderMan},
+	"markdown": {ext: ".md", render: renderMarkdown},
+	"rest":     {ext: ".rst", render: renderReST},
+}
+
+// GenerateDocs writes documentation for the Commander and each visible
+// command in format ("man", "markdown" or "rest") to dir. The overview is
+// written to "<name>.<ext>", each command to "<name>-<command>.<ext>", with
+// characters invalid in file names like ":" replaced by "_".
+func (c *Commander) GenerateDocs(dir, format string) error {
+	df, ok := docFormats[format]
+	if !ok {
+		return fmt.Errorf(c.tr("unsupported documentation format %q"), format)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, page := range c.docPages() {
+		if err := writeDocPage(filepath.Join(dir, docFileName(page.title)+df.ext), df, page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// docPages returns the overview page followed by a page for each visible
+// command, keyed by command name. The overview has an empty name.
+func (c *Commander) docPages() map[string]*docPage {
+	prog := filepath.Base(c.name)
+	spec := c.ExportSpec()
+	index := &docPage{
+		title: prog,
+		usage: fmt.Sprintf("%s [flags] <subcommand> [subcommand args]", prog),
+		flags: spec.Flags,
+	}
+	for _, g := range spec.Groups {
+		index.commands = append(index.commands, g.Commands...)
+	}
+	pages := map[string]*docPage{"": index}
+
+	for _, cs := range index.commands {
+		cmd, _ := c.Lookup(cs.Name)
+		pages[cs.Name] = &docPage{
+			title:       prog + "-" + cs.Name,
+			synopsis:    cs.Synopsis,
+			usage:       fmt.Sprintf("%s [flags] %s [subcommand flags]%s", prog, cs.Name, argsUsage(unwrap(cmd))),
+			description: cs.Usage,
+			examples:    cs.Examples,
+			flags:       cs.Flags,
+			seeAlso:     append([]string{prog}, cs.SeeAlso...),
+		}
+	}
+	return pages
+}
+
+// writeDocPage renders p into the file at path. The renderers ignore write
+// errors, they are reported by the buffered writer when flushing.
+func writeDocPage(path string, df docFormat, p *docPage) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	df.render(w, p)
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// docFileNameEscaper replaces characters which are invalid in file names on
+// some systems, like the ":" of namespaced commands on Windows.
+var docFileNameEscaper = strings.NewReplacer("<", "_", ">", "_", ":", "_", `"`, "_", "/", "_", `\`, "_", "|", "_", "?", "_", "*", "_")
+
+// docFileName returns the file name of the page with the specified title
+// without extension.
+func docFileName(title string) string { return docFileNameEscaper.Replace(title) }
+
+// flagName returns the flag as shown in documentation, e.g. "-o, --output string".
+func flagName(flag *FlagSpec) string {
+	name := "--" + flag.Name
+	if flag.Shorthand != "" {
+		name = "-" + flag.Shorthand + ", " + name
+	}
+	if flag.Type != "bool" {
+		name += " " + flag.Type
+	}
+	return name
+}
+
+var manEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+// manText escapes s for use as text lines of a man page. Lines starting
+// with "." or "'" would be read as requests, so they are prefixed with the
+// zero-width escape "\&".
+func manText(s string) string {
+	lines := strings.Split(manEscaper.Replace(s), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderMan(w io.Writer, p *docPage) {
+	fmt.Fprintf(w, ".TH %s 1\n", strings.ToUpper(manEscaper.Replace(p.title)))
+	fmt.Fprintf(w, ".SH NAME\n%s", manEscaper.Replace(p.title))
+	if p.synopsis != "" {
+		fmt.Fprintf(w, " \\- %s", manEscaper.Replace(p.synopsis))
+	}
+	fmt.Fprintf(w, "\n.SH SYNOPSIS\n.B %s\n", manEscaper.Replace(p.usage))
+	if p.description != "" {
+		fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", manText(p.description))
+	}
+	if len(p.commands) > 0 {
+		fmt.Fprintln(w, ".SH COMMANDS")
+		for _, cmd := range p.commands {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscaper.Replace(cmd.Name), manText(cmd.Synopsis))
+		}
+	}
+	if len(p.flags) > 0 {
+		fmt.Fprintln(w, ".SH OPTIONS")
+		for _, flag := range p.flags {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscaper.Replace(flagName(flag)), manText(flag.Usage))
+		}
+	}
+	if p.examples != "" {
+		fmt.Fprintf(w, ".SH EXAMPLES\n.nf\n%s\n.fi\n", manText(p.examples))
+	}
+	if len(p.seeAlso) > 0 {
+		refs := make([]string, len(p.seeAlso))
+		for i, ref := range p.seeAlso {
+			refs[i] = fmt.Sprintf(".BR %s (1)", manEscaper.Replace(ref))
+		}
+		fmt.Fprintf(w, ".SH SEE ALSO\n%s\n", strings.Join(refs, ",\n"))
+	}
+}
+
+func renderMarkdown(w io.Writer, p *docPage) {
+	fmt.Fprintf(w, "# %s\n\n", p.title)
+	if p.synopsis != "" {
+		fmt.Fprintf(w, "%s\n\n", p.synopsis)
+	}
+	fmt.Fprintf(w, "```\n%s\n```\n\n", p.usage)
+	if p.description != "" {
+		fmt.Fprintf(w, "%s\n\n", p.description)
+	}
+	if len(p.commands) > 0 {
+		fmt.Fprint(w, "## Commands\n\n")
+		for _, cmd := range p.commands {
+			fmt.Fprintf(w, "- [%s](%s.md): %s\n", cmd.Name, docFileName(p.title+"-"+cmd.Name), cmd.Synopsis)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(p.flags) > 0 {
+		fmt.Fprint(w, "## Options\n\n")
+		for _, flag := range p.flags {
+			fmt.Fprintf(w, "- `%s`: %s\n", flagName(flag), flag.Usage)
+		}
+		fmt.Fprintln(w)
+	}
+	if p.examples != "" {
+		fmt.Fprintf(w, "## Examples\n\n```\n%s\n```\n\n", p.examples)
+	}
+	if len(p.seeAlso) > 0 {
+		fmt.Fprint(w, "## See also\n\n")
+		for _, ref := range p.seeAlso {
+			fmt.Fprintf(w, "- [%s](%s.md)\n", ref, docFileName(ref))
+		}
+	}
+}
+
+func renderReST(w io.Writer, p *docPage) {
+	heading := func(title string, underline string) {
+		fmt.Fprintf(w, "%s\n%s\n\n", title, strings.Repeat(underline, len(title)))
+	}
+
+	heading(p.title, "=")
+	if p.synopsis != "" {
+		fmt.Fprintf(w, "%s\n\n", p.synopsis)
+	}
+	fmt.Fprintf(w, "::\n\n    %s\n\n", p.usage)
+	if p.description != "" {
+		fmt.Fprintf(w, "%s\n\n", p.description)
+	}
+	if len(p.commands) > 0 {
+		heading("Commands", "-")
+		for _, cmd := range p.commands {
+			fmt.Fprintf(w, "``%s``\n    %s\n\n", cmd.Name, cmd.Synopsis)
+		}
+	}
+	if len(p.flags) > 0 {
+		heading("Options", "-")
+		for _, flag := range p.flags {
+			fmt.Fprintf(w, "``%s``\n    %s\n\n", flagName(flag), flag.Usage)
+		}
+	}
+	if p.examples != "" {
+		heading("Examples", "-")
+		fmt.Fprintf(w, "::\n\n%s\n\n", indent(p.examples, "    "))
+	}
+	if len(p.seeAlso) > 0 {
+		heading("See also", "-")
+		for _, ref := range p.seeAlso {
+			fmt.Fprintf(w, "- :doc:`%s <%s>`\n", ref, docFileName(ref))
+		}
+	}
+}
+
+// renderTerminal renders p like a man page for reading in a terminal. If
+// styled, headings and names are highlighted with ANSI escape sequences
+// and text is wrapped to width.
+func renderTerminal(w io.Writer, p *docPage, styled bool, width int) {
+	bold := func(s string) string {
+		if !styled {
+			return s
+		}
+		return "\x1b[1m" + s + "\x1b[0m"
+	}
+	text := func(s string) string {
+		if width-docIndent >= minSynopsisWidth {
+			lines := strings.Split(s, "\n")
+			for i, line := range lines {
+				lines[i] = wrap(line, width-docIndent, "\n")
+			}
+			s = strings.Join(lines, "\n")
+		}
+		return indent(s, strings.Repeat(" ", docIndent))
+	}
+	section := func(title string) { fmt.Fprintf(w, "\n%s\n", bold(title)) }
+
+	fmt.Fprint(w, bold("NAME"), "\n")
+	name := p.title
+	if p.synopsis != "" {
+		name += " - " + p.synopsis
+	}
+	fmt.Fprintln(w, text(name))
+	section("SYNOPSIS")
+	fmt.Fprintln(w, text(p.usage))
+	if p.description != "" {
+		section("DESCRIPTION")
+		fmt.Fprintln(w, text(p.description))
+	}
+	if len(p.commands) > 0 {
+		section("COMMANDS")
+		for _, cmd := range p.commands {
+			fmt.Fprintf(w, "%s%s\n%s\n", strings.Repeat(" ", docIndent), bold(cmd.Name), text(indent(cmd.Synopsis, "    ")))
+		}
+	}
+	if len(p.flags) > 0 {
+		section("OPTIONS")
+		for _, flag := range p.flags {
+			fmt.Fprintf(w, "%s%s\n%s\n", strings.Repeat(" ", docIndent), bold(flagName(flag)), text(indent(flag.Usage, "    ")))
+		}
+	}
+	if p.examples != "" {
+		section("EXAMPLES")
+		fmt.Fprintln(w, indent(p.examples, strings.Repeat(" ", docIndent)))
+	}
+	if len(p.seeAlso) > 0 {
+		section("SEE ALSO")
+		fmt.Fprintln(w, text(strings.Join(p.seeAlso, ", ")))
+	}
+}
+
+// docIndent is the indentation of section contents rendered by renderTerminal.
+const docIndent = 4
+
+type docsCommand Commander
+
+// Name of this command.
+func (*docsCommand) Name() string { return "docs" }
+
+// Synopsis returns a short description of this command.
+func (d *docsCommand) Synopsis() string {
+	return (*Commander)(d).tr("generate documentation as man pages, markdown or reStructuredText")
+}
+
+// Usage returns the long description of this command.
+func (d *docsCommand) Usage() string {
+	return (*Commander)(d).tr("Without arguments the documentation is written to --dir. \"docs view\" shows the\n" +
+		"documentation of the program or of a command in the terminal instead.")
+}
+
+// Args returns the positional arguments of this command.
+func (*docsCommand) Args() ArgSpec {
+	return ArgSpec{Names: []string{"view", "command"}, Max: 2}
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (d *docsCommand) SetFlags(f *pflag.FlagSet) {
+	tr := (*Commander)(d).tr
+	f.String("format", "markdown", tr("documentation format (man, markdown, rest)"))
+	f.String("dir", ".", tr("directory to write the documentation to"))
+	MarkFlagDirname(f, "dir")
+}
+
+// Complete returns the supported documentation formats.
+func (*docsCommand) Complete(name, _ string) []string {
+	switch name {
+	case "format":
+		return sortedKeys(docFormats)
+	case "":
+		return []string{"view"}
+	}
+	return nil
+}
+
+// Execute executs this command and returns it's ExitStatus.
+func (d *docsCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	c := (*Commander)(d)
+	switch {
+	case f.NArg() > 0 && f.Arg(0) == "view":
+		return d.view(Streams(ctx), f.Arg(1))
+	case f.NArg() > 0:
+		return UsageErrorf(f, c.tr("unknown action %q, expected \"view\""), f.Arg(0))
+	}
+
+	format, _ := f.GetString("format")
+	dir, _ := f.GetString("dir")
+	if _, ok := docFormats[format]; !ok {
+		return UsageErrorf(f, (*Commander)(d).tr("unsupported documentation format %q"), format)
+	}
+	if err := (*Commander)(d).GenerateDocs(dir, format); err != nil {
+		fmt.Fprintf(Streams(ctx).Err, (*Commander)(d).tr("Failed to generate documentation: %s\n"), err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// view shows the documentation of the command name, or of the program if
+// name is empty, in the terminal.
+func (d *docsCommand) view(streams *IOStreams, name string) ExitStatus {
+	c := (*Commander)(d)
+	pages := c.docPages()
+	page, ok := pages[name]
+	if !ok {
+		delete(pages, "")
+		fmt.Fprintln(streams.Err, CheckValue(c.tr("command"), name, sortedKeys(pages)))
+		return ExitUsageError
+	}
+
+	styled := isTerminal(streams.Out) && enableVirtualTerminal(streams.Out)
+	width := terminalWidth(streams.Out)
+	c.writeHelp(streams.Out, func(w io.Writer) { renderTerminal(w, page, styled, width) })
+	return ExitSuccess
+}
+
+// RegisterDocsCommand registers the "docs" command generating documentation
+// to the specified group, or showing it with "docs view [command]".
+func (c *Commander) RegisterDocsCommand(group string) { c.Register(group, (*docsCommand)(c)) }
+
+// RegisterDocsCommand registers the docs command on the DefaultCommander.
+func RegisterDocsCommand(group string) { DefaultCommander.RegisterDocsCommand(group) }
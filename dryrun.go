@@ -0,0 +1,44 @@
+package psubcommands
+
+import (
+	"context"
+
+	"github.com/spf13/pflag"
+)
+
+// DryRunFlag is the name of the flag added to commands implementing DryRunner.
+const DryRunFlag = "dry-run"
+
+// DryRunner may be implemented by a mutating Command supporting dry runs.
+// The Commander adds a --dry-run flag to such commands, whose value is
+// available through IsDryRun.
+type DryRunner interface {
+	SupportsDryRun() bool
+}
+
+func supportsDryRun(cmd Command) bool {
+	d, ok := unwrap(cmd).(DryRunner)
+	return ok && d.SupportsDryRun()
+}
+
+// addDryRunFlag adds the --dry-run flag to f if cmd supports dry runs.
+func (c *Commander) addDryRunFlag(cmd Command, f *pflag.FlagSet) {
+	if supportsDryRun(cmd) && f.Lookup(DryRunFlag) == nil {
+		f.Bool(DryRunFlag, false, c.tr("only show what would be done without changing anything"))
+	}
+}
+
+// withDryRun stores the value of the --dry-run flag of f in ctx.
+func withDryRun(ctx context.Context, cmd Command, f *pflag.FlagSet) context.Context {
+	if !supportsDryRun(cmd) {
+		return ctx
+	}
+	dryRun, _ := f.GetBool(DryRunFlag)
+	return context.WithValue(ctx, dryRunKey, dryRun)
+}
+
+// IsDryRun reports whether the current command was invoked with --dry-run.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey).(bool)
+	return dryRun
+}
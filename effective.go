@@ -0,0 +1,119 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagSource tells where the effective value of a flag came from.
+type FlagSource string
+
+const (
+	// SourceFlag is a value given on the command line.
+	SourceFlag FlagSource = "flag"
+	// SourcePreset is a value of the preset selected with --preset, see
+	// RegisterPreset.
+	SourcePreset FlagSource = "preset"
+	// SourceRC is a value read from the rc file, see EnableRCFile.
+	SourceRC FlagSource = "rc"
+	// SourceDefault is the default value of the flag.
+	SourceDefault FlagSource = "default"
+)
+
+// EffectiveFlag is the effective value of a flag and its source.
+type EffectiveFlag struct {
+	Name   string     `json:"name" yaml:"name" table:"flag"`
+	Value  string     `json:"value" yaml:"value" table:"value"`
+	Source FlagSource `json:"source" yaml:"source" table:"source"`
+}
+
+// EffectiveFlags parses argv for the command with the specified name like
+// Execute would and returns the effective value of every flag of the command
+// along with its source. Values of secret flags are masked.
+func (c *Commander) EffectiveFlags(name string, argv []string) ([]EffectiveFlag, error) {
+	cmd, ok := c.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf(c.tr("unknown subcommand %q"), name)
+	}
+	cmd = unwrap(cmd)
+
+	f := c.commandFlags(cmd)
+	f.SetOutput(io.Discard)
+	if c.InterspersedGlobalFlags {
+		c.mergeGlobalFlags(f)
+	}
+	if err := f.Parse(argv); err != nil {
+		return nil, fmt.Errorf("%s", maskError(err))
+	}
+
+	sources := map[string]FlagSource{}
+	f.Visit(func(flag *pflag.Flag) { sources[flag.Name] = SourceFlag })
+	preset, err := c.setPreset(cmd, f)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range preset {
+		sources[name] = SourcePreset
+	}
+	rc, err := c.setRCDefaults(cmd, f)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range rc {
+		sources[name] = SourceRC
+	}
+
+	flags := []EffectiveFlag{}
+	f.VisitAll(func(flag *pflag.Flag) {
+		source, ok := sources[flag.Name]
+		if !ok {
+			source = SourceDefault
+		}
+		flags = append(flags, EffectiveFlag{Name: flag.Name, Value: FlagValue(flag), Source: source})
+	})
+	return flags, nil
+}
+
+type configCommand Commander
+
+// Name of this command.
+func (*configCommand) Name() string { return "config" }
+
+// Synopsis returns a short description of this command.
+func (c *configCommand) Synopsis() string {
+	return (*Commander)(c).tr("show the effective flag values of a subcommand and their sources")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*configCommand) SetFlags(f *pflag.FlagSet) { f.SetInterspersed(false) }
+
+// Args returns the positional arguments of this command.
+func (*configCommand) Args() ArgSpec {
+	return ArgSpec{Names: []string{"subcommand", "flags"}, Min: 1, Max: -1}
+}
+
+// OutputFormats returns the supported output formats.
+func (*configCommand) OutputFormats() []string { return nil }
+
+// Execute executs this command and returns it's ExitStatus.
+func (c *configCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	flags, err := (*Commander)(c).EffectiveFlags(f.Arg(0), f.Args()[1:])
+	if err != nil {
+		return UsageErrorf(f, "%s", err)
+	}
+	if err := Render(ctx, OutputFormat(ctx), flags); err != nil {
+		fmt.Fprintf(c.Error, "%s\n", err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// RegisterConfigCommand registers the "config" command reporting the
+// effective flag values of a subcommand to the specified group.
+func (c *Commander) RegisterConfigCommand(group string) { c.Register(group, (*configCommand)(c)) }
+
+// RegisterConfigCommand registers the config command on the DefaultCommander.
+func RegisterConfigCommand(group string) { DefaultCommander.RegisterConfigCommand(group) }
@@ -0,0 +1,62 @@
+package psubcommands
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvFunc looks up the environment variable key like os.LookupEnv.
+type EnvFunc func(key string) (string, bool)
+
+// EnvMap returns an EnvFunc looking up variables in env.
+func EnvMap(env map[string]string) EnvFunc {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+// WithEnv returns a copy of ctx which makes the commands executed with it
+// see env instead of the environment configured on the Commander, e.g. to
+// give each Execute call a synthetic environment.
+func WithEnv(ctx context.Context, env EnvFunc) context.Context {
+	return context.WithValue(ctx, envKey, env)
+}
+
+// LookupEnv looks up the environment variable key in the environment of the
+// current command, see Commander.LookupEnv and WithEnv. If ctx doesn't
+// provide an environment the process environment is used.
+func LookupEnv(ctx context.Context, key string) (string, bool) {
+	if env, ok := ctx.Value(envKey).(EnvFunc); ok {
+		return env(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// Getenv returns the value of the environment variable key in the
+// environment of the current command, see LookupEnv.
+func Getenv(ctx context.Context, key string) string {
+	value, _ := LookupEnv(ctx, key)
+	return value
+}
+
+// envTrue reports whether value of an environment variable enables a feature.
+func envTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no":
+		return false
+	}
+	return true
+}
+
+// getenv returns the value of the environment variable key in the
+// environment of the Commander.
+func (c *Commander) getenv(key string) string {
+	lookup := c.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	value, _ := lookup(key)
+	return value
+}
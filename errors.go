@@ -0,0 +1,105 @@
+package psubcommands
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDuplicateCommand is returned if a command with the same name is
+	// registered already.
+	ErrDuplicateCommand = errors.New("duplicate command")
+	// ErrUnknownCommand is returned if no command with the name is registered.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrBadFlagSet is returned if SetFlags of a command panics, e.g.
+	// because it defines a flag twice.
+	ErrBadFlagSet = errors.New("bad flag set")
+	// ErrEmptyName is returned if a command with an empty name is registered.
+	ErrEmptyName = errors.New("empty command name")
+)
+
+// CommandError reports a failed operation on a command. Err is one of
+// ErrDuplicateCommand, ErrUnknownCommand, ErrBadFlagSet or ErrEmptyName, so
+// the cause can be checked with errors.Is.
+type CommandError struct {
+	Op     string // operation, e.g. "register"
+	Name   string // name of the command
+	Err    error  // cause of the failure
+	Detail string // additional information, e.g. a recovered panic
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("psubcommands: %s %s: %s", e.Op, e.Name, e.Err)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the failure.
+func (e *CommandError) Unwrap() error { return e.Err }
+
+// TryRegister is like Register, but validates cmds first. It returns a
+// *CommandError and registers none of cmds if a name is empty, registered
+// already or used twice in cmds, or if SetFlags of a command panics. Lazily
+// registered commands aren't constructed to check their flags.
+func (c *Commander) TryRegister(group string, cmds ...Command) error {
+	seen := map[string]bool{}
+	for _, cmd := range cmds {
+		name := cmd.Name()
+		if name == "" {
+			return &CommandError{Op: "register", Name: name, Err: ErrEmptyName}
+		}
+		if _, ok := c.Lookup(name); ok || seen[name] {
+			return &CommandError{Op: "register", Name: name, Err: ErrDuplicateCommand}
+		}
+		seen[name] = true
+		if err := c.checkFlagSet("register", cmd); err != nil {
+			return err
+		}
+	}
+	c.Register(group, cmds...)
+	return nil
+}
+
+// TryUnregister is like Unregister, but returns a *CommandError wrapping
+// ErrUnknownCommand if no command with the name is registered.
+func (c *Commander) TryUnregister(name string) error {
+	if !c.Unregister(name) {
+		return &CommandError{Op: "unregister", Name: name, Err: ErrUnknownCommand}
+	}
+	return nil
+}
+
+// TryReplace is like Replace, but validates the flags of cmd first and
+// returns a *CommandError wrapping ErrUnknownCommand if no command with the
+// name is registered.
+func (c *Commander) TryReplace(name string, cmd Command) error {
+	if err := c.checkFlagSet("replace", cmd); err != nil {
+		return err
+	}
+	if !c.Replace(name, cmd) {
+		return &CommandError{Op: "replace", Name: name, Err: ErrUnknownCommand}
+	}
+	return nil
+}
+
+// checkFlagSet calls SetFlags of cmd and reports a panic as ErrBadFlagSet.
+func (c *Commander) checkFlagSet(op string, cmd Command) (err error) {
+	if _, lazy := cmd.(*lazyCommand); lazy {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &CommandError{Op: op, Name: cmd.Name(), Err: ErrBadFlagSet, Detail: fmt.Sprint(r)}
+		}
+	}()
+	c.commandFlags(cmd)
+	return nil
+}
+
+// TryRegister registers the given commands on the DefaultCommander, see
+// Commander.TryRegister.
+func TryRegister(group string, cmds ...Command) error {
+	return DefaultCommander.TryRegister(group, cmds...)
+}
@@ -0,0 +1,90 @@
+package psubcommands
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Invocation is a single command line executed by ExecuteAll.
+type Invocation struct {
+	// Args is the command line starting with the subcommand name.
+	Args []string
+
+	// Input is passed to the command as its In stream.
+	Input string
+
+	// Env, if not nil, is the environment of the command, see LookupEnv.
+	Env map[string]string
+}
+
+// InvocationResult is the result of an Invocation.
+type InvocationResult struct {
+	Invocation Invocation
+	Status     ExitStatus
+	Duration   time.Duration
+
+	// Stdout and Stderr hold the output the command wrote to its Streams.
+	Stdout string
+	Stderr string
+}
+
+// ExecuteAll executes the invocations with at most concurrency of them
+// running at the same time and returns their results in the order of
+// invocations. A concurrency below 1 runs all invocations at once.
+//
+// Every invocation gets its own Streams, so commands must write their output
+// to Streams(ctx) for it to be captured. Messages of the Commander itself,
+// e.g. usage errors, are written to Error. Commander.WorkDir, Env and
+// their per command overrides change the whole process and aren't isolated
+// between concurrent invocations.
+func (c *Commander) ExecuteAll(ctx context.Context, invocations []Invocation, concurrency int) []InvocationResult {
+	if concurrency < 1 {
+		concurrency = len(invocations)
+	}
+
+	results := make([]InvocationResult, len(invocations))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, inv := range invocations {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, inv Invocation) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = c.invoke(ctx, inv)
+		}(i, inv)
+	}
+	wg.Wait()
+	return results
+}
+
+// invoke executes inv with captured streams.
+func (c *Commander) invoke(ctx context.Context, inv Invocation) InvocationResult {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	ctx = context.WithValue(ctx, invocationStreamsKey, &IOStreams{
+		In:  strings.NewReader(inv.Input),
+		Out: stdout,
+		Err: stderr,
+	})
+	if inv.Env != nil {
+		ctx = WithEnv(ctx, EnvMap(inv.Env))
+	}
+
+	start := time.Now()
+	status := ExitUsageError
+	if len(inv.Args) > 0 {
+		status = c.dispatch(ctx, inv.Args)
+	}
+	return InvocationResult{
+		Invocation: inv,
+		Status:     status,
+		Duration:   time.Since(start),
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+	}
+}
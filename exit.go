@@ -0,0 +1,34 @@
+package psubcommands
+
+import (
+	"context"
+	"os"
+)
+
+// AtExit registers fn to be called by ExecuteAndExit before the process exits.
+// Functions are called in reverse order of registration.
+func (c *Commander) AtExit(fn func()) { c.atExit = append(c.atExit, fn) }
+
+// ExecuteAndExit executes the Commander, flushes its output within
+// FlushTimeout, runs the functions registered with AtExit and exits the
+// process with the returned ExitStatus.
+// On POSIX systems statuses outside 0-255 exit with ExitFailure.
+func (c *Commander) ExecuteAndExit(ctx context.Context, args ...interface{}) {
+	status := c.Execute(ctx, args...)
+
+	c.flushOutput(ctx, c.Output, c.Error)
+	for i := len(c.atExit) - 1; i >= 0; i-- {
+		c.atExit[i]()
+	}
+
+	os.Exit(exitCode(status))
+}
+
+// AtExit registers fn to be called by ExecuteAndExit on the DefaultCommander.
+func AtExit(fn func()) { DefaultCommander.AtExit(fn) }
+
+// ExecuteAndExit executes the DefaultCommander and exits the process with the
+// returned ExitStatus.
+func ExecuteAndExit(ctx context.Context, args ...interface{}) {
+	DefaultCommander.ExecuteAndExit(ctx, args...)
+}
@@ -0,0 +1,150 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ExitCodeDeclarer may be implemented by a Command returning exit statuses
+// besides the predefined ones. They are listed by the exit-codes command.
+type ExitCodeDeclarer interface {
+	ExitCodes() map[ExitStatus]string
+}
+
+// ExitCodeSpec describes an exit status the program may return.
+type ExitCodeSpec struct {
+	Code        int      `json:"code" yaml:"code"`
+	Description string   `json:"description" yaml:"description"`
+	Commands    []string `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// frameworkExitCodes describes the exit statuses returned by the Commander.
+var frameworkExitCodes = map[ExitStatus]string{
+	ExitSuccess:          "success",
+	ExitFailure:          "generic failure",
+	ExitUsageError:       "invalid command line",
+	ExitTimeout:          "timeout exceeded",
+	ExitPermissionDenied: "permission denied",
+	ExitLocked:           "locked by another instance",
+	ExitCancelled:        "cancelled, e.g. by a signal",
+}
+
+// RegisterExitCode documents an exit status returned by the program, e.g.
+// by an error mapped with RegisterErrorStatus, for the exit-codes command.
+func (c *Commander) RegisterExitCode(status ExitStatus, description string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.exitCodes == nil {
+		c.exitCodes = map[ExitStatus]string{}
+	}
+	c.exitCodes[status] = description
+}
+
+// RegisterExitCode documents an exit status of the DefaultCommander.
+func RegisterExitCode(status ExitStatus, description string) {
+	DefaultCommander.RegisterExitCode(status, description)
+}
+
+// ExitCodes returns all exit statuses the program may return, ordered by
+// code: the predefined ones, those registered with RegisterExitCode and
+// those declared by commands implementing ExitCodeDeclarer.
+func (c *Commander) ExitCodes() []*ExitCodeSpec {
+	type key struct {
+		code        ExitStatus
+		description string
+	}
+	specs := map[key]*ExitCodeSpec{}
+	add := func(code ExitStatus, description, command string) {
+		k := key{code, description}
+		spec, ok := specs[k]
+		if !ok {
+			spec = &ExitCodeSpec{Code: int(code), Description: description}
+			specs[k] = spec
+		}
+		if command != "" {
+			spec.Commands = append(spec.Commands, command)
+		}
+	}
+
+	for code, description := range frameworkExitCodes {
+		add(code, c.tr(description), "")
+	}
+	c.mu.RLock()
+	for code, description := range c.exitCodes {
+		add(code, description, "")
+	}
+	c.mu.RUnlock()
+	c.VisitCommands(func(_ string, cmd Command) {
+		if d, ok := unwrap(cmd).(ExitCodeDeclarer); ok {
+			for code, description := range d.ExitCodes() {
+				add(code, description, cmd.Name())
+			}
+		}
+	})
+
+	list := make([]*ExitCodeSpec, 0, len(specs))
+	for _, spec := range specs {
+		list = append(list, spec)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Code != list[j].Code {
+			return list[i].Code < list[j].Code
+		}
+		return list[i].Description < list[j].Description
+	})
+	return list
+}
+
+type exitCodesCommand Commander
+
+// Name of this command.
+func (*exitCodesCommand) Name() string { return "exit-codes" }
+
+// Synopsis returns a short description of this command.
+func (e *exitCodesCommand) Synopsis() string {
+	return (*Commander)(e).tr("list the exit statuses of this program")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*exitCodesCommand) SetFlags(*pflag.FlagSet) {}
+
+// OutputFormats returns the supported output formats.
+func (*exitCodesCommand) OutputFormats() []string { return nil }
+
+// Execute executs this command and returns it's ExitStatus.
+func (e *exitCodesCommand) Execute(ctx context.Context, _ *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	c := (*Commander)(e)
+	streams := Streams(ctx)
+	codes := c.ExitCodes()
+
+	if format := OutputFormat(ctx); format != DefaultOutputFormat {
+		if err := Render(ctx, format, codes); err != nil {
+			fmt.Fprintln(streams.Err, err)
+			return ExitFailure
+		}
+		return ExitSuccess
+	}
+
+	t := NewTable(ctx, c.tr("CODE"), c.tr("DESCRIPTION"), c.tr("COMMANDS"))
+	for _, spec := range codes {
+		t.Append(spec.Code, spec.Description, strings.Join(spec.Commands, ", "))
+	}
+	if err := t.Render(); err != nil {
+		fmt.Fprintln(streams.Err, err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// RegisterExitCodesCommand registers the "exit-codes" command listing all
+// exit statuses of the program to the specified group, see ExitCodes.
+func (c *Commander) RegisterExitCodesCommand(group string) {
+	c.Register(group, (*exitCodesCommand)(c))
+}
+
+// RegisterExitCodesCommand registers the exit-codes command on the DefaultCommander.
+func RegisterExitCodesCommand(group string) { DefaultCommander.RegisterExitCodesCommand(group) }
@@ -0,0 +1,183 @@
+package psubcommands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// CommanderSpec describes the complete command line surface of a Commander.
+type CommanderSpec struct {
+	Name      string              `json:"name"`
+	UsageLine string              `json:"usage_line"`
+	Flags     []*FlagSpec         `json:"flags,omitempty"`
+	Groups    []*CommandGroupSpec `json:"groups"`
+}
+
+// CommandGroupSpec describes a single group of commands.
+type CommandGroupSpec struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Commands    []*CommandSpec `json:"commands"`
+}
+
+// CommandSpec describes a single command.
+type CommandSpec struct {
+	Name        string            `json:"name"`
+	UsageLine   string            `json:"usage_line"`
+	Synopsis    string            `json:"synopsis"`
+	Usage       string            `json:"usage,omitempty"`
+	Args        *ArgsSpec         `json:"args,omitempty"`
+	Examples    string            `json:"examples,omitempty"`
+	SeeAlso     []string          `json:"see_also,omitempty"`
+	Flags       []*FlagSpec       `json:"flags,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ArgsSpec describes the positional arguments of a command, see ArgSpec.
+type ArgsSpec struct {
+	Names       []string `json:"names,omitempty"`
+	Min         int      `json:"min"`
+	Max         int      `json:"max"`
+	PassThrough string   `json:"pass_through,omitempty"`
+}
+
+// FlagSpec describes a single flag.
+type FlagSpec struct {
+	Name       string   `json:"name"`
+	Shorthand  string   `json:"shorthand,omitempty"`
+	Type       string   `json:"type"`
+	Usage      string   `json:"usage"`
+	Default    string   `json:"default"`
+	Deprecated string   `json:"deprecated,omitempty"`
+	Enum       []string `json:"enum,omitempty"`
+}
+
+// ExportSpec returns a description of all groups, commands and flags
+// registered on this Commander.
+func (c *Commander) ExportSpec() *CommanderSpec {
+	spec := &CommanderSpec{
+		Name:      c.name,
+		UsageLine: fmt.Sprintf("%s <flags> <subcommand> <subcommand args>", c.name),
+		Flags:     exportFlags(c.topFlags),
+		Groups:    []*CommandGroupSpec{},
+	}
+
+	for _, group := range c.orderedGroups() {
+		g := &CommandGroupSpec{
+			Name:        group.name,
+			Description: group.description,
+			Commands:    []*CommandSpec{},
+		}
+		for _, cmd := range group.commands {
+			g.Commands = append(g.Commands, c.exportCommand(cmd))
+		}
+		spec.Groups = append(spec.Groups, g)
+	}
+
+	return spec
+}
+
+// MarshalJSON implements json.Marshaler by encoding the result of ExportSpec.
+func (c *Commander) MarshalJSON() ([]byte, error) { return json.Marshal(c.ExportSpec()) }
+
+func (c *Commander) exportCommand(cmd Command) *CommandSpec {
+	f := c.commandFlags(cmd)
+	spec := &CommandSpec{
+		Name:        cmd.Name(),
+		UsageLine:   fmt.Sprintf("%s <flags> %s <subcommand flags>%s", c.name, cmd.Name(), argsUsage(unwrap(cmd))),
+		Synopsis:    cmd.Synopsis(),
+		Flags:       exportFlags(f),
+		Annotations: c.Annotations(cmd),
+	}
+	switch u := unwrap(cmd).(type) {
+	case LongUsager:
+		spec.Usage = u.Usage()
+	case Usager:
+		var buf strings.Builder
+		u.Usage(&buf)
+		spec.Usage = buf.String()
+	}
+	if e, ok := unwrap(cmd).(Exampler); ok {
+		spec.Examples = e.Examples()
+	}
+	if s, ok := unwrap(cmd).(SeeAlsoer); ok {
+		spec.SeeAlso = s.SeeAlso()
+	}
+	if a, ok := argSpec(unwrap(cmd)); ok {
+		spec.Args = &ArgsSpec{Names: a.Names, Min: a.Min, Max: a.Max, PassThrough: a.PassThrough}
+	}
+	return spec
+}
+
+func exportFlags(f *pflag.FlagSet) []*FlagSpec {
+	flags := []*FlagSpec{}
+	f.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		def := flag.DefValue
+		if isSecret(flag) && def != "" {
+			def = MaskedValue
+		}
+		deprecated := flag.Deprecated
+		if notice, ok := deprecation(identity, flag); ok && deprecated == "" {
+			deprecated = notice
+		}
+		flags = append(flags, &FlagSpec{
+			Name:       flag.Name,
+			Shorthand:  flag.Shorthand,
+			Type:       flag.Value.Type(),
+			Usage:      flag.Usage,
+			Default:    def,
+			Deprecated: deprecated,
+			Enum:       flag.Annotations[annotationEnum],
+		})
+	})
+	return flags
+}
+
+// HelpFormatJSON is the format requested by --help=json to print help as
+// JSON to Output, see ExportSpec.
+const HelpFormatJSON = "json"
+
+// helpFormat returns the format requested with --help=<format> in argv or
+// an empty string for text help.
+func helpFormat(argv []string) string {
+	for _, arg := range argv {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "--help=") {
+			return strings.TrimPrefix(arg, "--help=")
+		}
+	}
+	return ""
+}
+
+// writeJSONHelp writes v as indented JSON to w.
+func (c *Commander) writeJSONHelp(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(c.Error, c.tr("Failed to encode help: %s\n"), err)
+	}
+}
+
+// usage is the Usage function of the top-level FlagSet. Help requested
+// explicitly goes to Output, usage shown for errors to Error.
+func (c *Commander) usage() {
+	if c.helpFormat == HelpFormatJSON {
+		c.writeJSONHelp(c.Output, c.ExportSpec())
+		return
+	}
+	if c.helpRequested {
+		c.Explain(c.Output)
+		return
+	}
+	c.Explain(c.Error)
+}
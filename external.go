@@ -0,0 +1,41 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+// externalCommand looks up an executable named <name>-<subcommand> in PATH.
+func (c *Commander) externalCommand(subcommand string) (string, bool) {
+	if !c.ExternalCommands || subcommand == "" {
+		return "", false
+	}
+	path, err := exec.LookPath(fmt.Sprintf("%s-%s", filepath.Base(c.name), subcommand))
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// executeExternal runs the external command at path with argv and returns its exit code.
+func (c *Commander) executeExternal(ctx context.Context, path string, argv []string) ExitStatus {
+	cmd := exec.CommandContext(ctx, path, argv...)
+	cmd.Stdin = c.Input
+	cmd.Stdout = c.Output
+	cmd.Stderr = c.Error
+
+	err := cmd.Run()
+	if err == nil {
+		return ExitSuccess
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return ExitStatus(exitErr.ExitCode())
+	}
+	fmt.Fprintf(c.Error, c.tr("Failed to execute %s: %s\n"), path, err)
+	return ExitFailure
+}
@@ -0,0 +1,64 @@
+package psubcommands
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// DeprecateFlag marks the flag name of command as deprecated in favor of
+// replacement, e.g. "--region", which may be empty. When the flag is used
+// a warning is printed once per invocation and help output shows a
+// deprecation notice. Flags can also be marked in SetFlags with
+// MarkFlagDeprecated.
+func (c *Commander) DeprecateFlag(command, name, replacement string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deprecated == nil {
+		c.deprecated = map[string]map[string]string{}
+	}
+	if c.deprecated[command] == nil {
+		c.deprecated[command] = map[string]string{}
+	}
+	c.deprecated[command][name] = replacement
+	c.help = nil
+}
+
+// DeprecateFlag marks a flag of a command of the DefaultCommander as deprecated.
+func DeprecateFlag(command, name, replacement string) {
+	DefaultCommander.DeprecateFlag(command, name, replacement)
+}
+
+// markDeprecatedFlags marks the flags of f deprecated with DeprecateFlag.
+func (c *Commander) markDeprecatedFlags(cmd Command, f *pflag.FlagSet) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, replacement := range c.deprecated[cmd.Name()] {
+		if f.Lookup(name) != nil {
+			_ = MarkFlagDeprecated(f, name, replacement)
+		}
+	}
+}
+
+// deprecation returns the deprecation notice of flag translated with tr,
+// if any.
+func deprecation(tr func(string) string, flag *pflag.Flag) (string, bool) {
+	replacement, ok := flag.Annotations[annotationDeprecated]
+	switch {
+	case !ok:
+		return "", false
+	case len(replacement) == 0 || replacement[0] == "":
+		return tr("deprecated"), true
+	}
+	return fmt.Sprintf(tr("deprecated, use %s instead"), replacement[0]), true
+}
+
+// warnDeprecated prints a warning for every deprecated flag set on the
+// command line of f.
+func (c *Commander) warnDeprecated(f *pflag.FlagSet) {
+	f.Visit(func(flag *pflag.Flag) {
+		if notice, ok := deprecation(c.tr, flag); ok {
+			fmt.Fprintf(f.Output(), c.tr("Warning: --%s is %s\n"), flag.Name, notice)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package psubcommands
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	annotationRequired   = "psubcommands_required"
+	annotationSecret     = "psubcommands_secret"
+	annotationFilename   = "psubcommands_filename"
+	annotationDirname    = "psubcommands_dirname"
+	annotationSection    = "psubcommands_section"
+	annotationEnum       = "psubcommands_enum"
+	annotationDeprecated = "psubcommands_deprecated"
+)
+
+// MarkFlagRequired marks the named flag as required. If a required flag
+// wasn't provided on the command line the Commander either prompts for it
+// (see Commander.PromptMissing) or fails with ExitUsageError.
+func MarkFlagRequired(f *pflag.FlagSet, name string) error {
+	return f.SetAnnotation(name, annotationRequired, []string{"true"})
+}
+
+// MaskedValue replaces the values of secret flags in output of the Commander.
+const MaskedValue = "********"
+
+// MarkFlagSecret marks the named flag as secret. Values of secret flags
+// are read with hidden input when prompted for and masked with MaskedValue
+// in hook events, help output and error messages.
+func MarkFlagSecret(f *pflag.FlagSet, name string) error {
+	return f.SetAnnotation(name, annotationSecret, []string{"true"})
+}
+
+// MarkFlagFilename marks the named flag as expecting a file name. Shell
+// completion falls back to native file completion, optionally limited to
+// the given extensions.
+func MarkFlagFilename(f *pflag.FlagSet, name string, extensions ...string) error {
+	return f.SetAnnotation(name, annotationFilename, trimDots(extensions))
+}
+
+// MarkFlagDirname marks the named flag as expecting a directory name. Shell
+// completion falls back to native directory completion.
+func MarkFlagDirname(f *pflag.FlagSet, name string) error {
+	return f.SetAnnotation(name, annotationDirname, []string{"true"})
+}
+
+// MarkFlagEnum restricts the named flag to values. The Commander rejects
+// other values with ExitUsageError after parsing, lists the values in help
+// output and offers them for shell completion. Every element of a slice
+// flag must be one of values. The default value is always accepted.
+func MarkFlagEnum(f *pflag.FlagSet, name string, values ...string) error {
+	return f.SetAnnotation(name, annotationEnum, values)
+}
+
+// Enum defines a string flag restricted to values, see MarkFlagEnum.
+func Enum(f *pflag.FlagSet, name, value, usage string, values ...string) *string {
+	return EnumP(f, name, "", value, usage, values...)
+}
+
+// EnumP is like Enum, but accepts a shorthand letter.
+func EnumP(f *pflag.FlagSet, name, shorthand, value, usage string, values ...string) *string {
+	p := f.StringP(name, shorthand, value, usage)
+	_ = MarkFlagEnum(f, name, values...)
+	return p
+}
+
+// MarkFlagDeprecated marks the named flag as deprecated in favor of
+// replacement, e.g. "--region", which may be empty. Unlike
+// pflag.FlagSet.MarkDeprecated the flag stays visible in help output with
+// a deprecation notice, and using it prints a warning, see
+// Commander.DeprecateFlag.
+func MarkFlagDeprecated(f *pflag.FlagSet, name, replacement string) error {
+	return f.SetAnnotation(name, annotationDeprecated, []string{replacement})
+}
+
+// SetFlagSection assigns the named flags to section. Help output lists the
+// flags of each section in a separate block below the remaining flags.
+func SetFlagSection(f *pflag.FlagSet, section string, names ...string) error {
+	for _, name := range names {
+		if err := f.SetAnnotation(name, annotationSection, []string{section}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flagSections splits the flags of f into the unsectioned flags and the
+// flags of each section, in order of the sections' first flag.
+func flagSections(f *pflag.FlagSet) (*pflag.FlagSet, []string, map[string]*pflag.FlagSet) {
+	newSet := func() *pflag.FlagSet {
+		s := pflag.NewFlagSet(f.Name(), pflag.ContinueOnError)
+		s.SortFlags = f.SortFlags
+		return s
+	}
+
+	rest := newSet()
+	names := []string{}
+	sections := map[string]*pflag.FlagSet{}
+	// Keep the declaration order, flagUsages sorts the flags if requested.
+	sortFlags := f.SortFlags
+	f.SortFlags = false
+	defer func() { f.SortFlags = sortFlags }()
+	f.VisitAll(func(flag *pflag.Flag) {
+		if isSecret(flag) && flag.DefValue != "" {
+			masked := *flag
+			masked.DefValue = MaskedValue
+			flag = &masked
+		}
+		section := flag.Annotations[annotationSection]
+		if len(section) == 0 {
+			rest.AddFlag(flag)
+			return
+		}
+		s, ok := sections[section[0]]
+		if !ok {
+			s = newSet()
+			sections[section[0]] = s
+			names = append(names, section[0])
+		}
+		s.AddFlag(flag)
+	})
+	return rest, names, sections
+}
+
+func hasAnnotation(flag *pflag.Flag, key string) bool {
+	_, ok := flag.Annotations[key]
+	return ok
+}
+
+func isRequired(flag *pflag.Flag) bool { return hasAnnotation(flag, annotationRequired) }
+
+func isSecret(flag *pflag.Flag) bool { return hasAnnotation(flag, annotationSecret) }
+
+// FlagValue returns the value of flag or MaskedValue if flag is secret and
+// not empty. Use it when recording flag values, e.g. in telemetry or audit logs.
+func FlagValue(flag *pflag.Flag) string {
+	value := flag.Value.String()
+	if isSecret(flag) && value != "" {
+		return MaskedValue
+	}
+	return value
+}
+
+// maskError returns the message of err with the value of a secret flag
+// masked, if err reports an invalid value for one.
+func maskError(err error) string {
+	var invalid *pflag.InvalidValueError
+	if errors.As(err, &invalid) && isSecret(invalid.GetFlag()) && invalid.GetValue() != "" {
+		return strings.ReplaceAll(err.Error(), invalid.GetValue(), MaskedValue)
+	}
+	return err.Error()
+}
+
+// maskValue returns the message of err with value masked if flag is secret.
+func maskValue(flag *pflag.Flag, value string, err error) string {
+	if isSecret(flag) && value != "" {
+		return strings.ReplaceAll(err.Error(), value, MaskedValue)
+	}
+	return err.Error()
+}
+
+// invalidEnum returns the first value of flag not allowed by its enum
+// annotation.
+func invalidEnum(flag *pflag.Flag) (string, bool) {
+	allowed, ok := flag.Annotations[annotationEnum]
+	if !ok {
+		return "", false
+	}
+
+	values := []string{flag.Value.String()}
+	if s, ok := unwrapValue(flag.Value).(pflag.SliceValue); ok {
+		values = s.GetSlice()
+	}
+	for _, v := range values {
+		if !contains(allowed, v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// missingFlags returns all required flags which weren't set on the command line.
+func missingFlags(f *pflag.FlagSet) []*pflag.Flag {
+	missing := []*pflag.Flag{}
+	f.VisitAll(func(flag *pflag.Flag) {
+		if isRequired(flag) && !flag.Changed {
+			missing = append(missing, flag)
+		}
+	})
+	return missing
+}
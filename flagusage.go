@@ -0,0 +1,143 @@
+package psubcommands
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagOrder defines the order in which flags are listed in help output.
+type FlagOrder int
+
+const (
+	// FlagOrderFlagSet lists flags sorted by name unless SortFlags of the
+	// FlagSet is disabled, like pflag does.
+	FlagOrderFlagSet FlagOrder = iota
+	// FlagOrderLexical lists flags sorted by name.
+	FlagOrderLexical
+	// FlagOrderDeclaration lists flags in the order they were defined.
+	FlagOrderDeclaration
+)
+
+// maxFlagColumn limits the column at which flag usages start. Usages of
+// flags with longer names start on the next line.
+const maxFlagColumn = 32
+
+// flagUsages formats the visible flags of f for help output written to w,
+// ordered as configured by FlagOrder and GroupShorthandFlags. Usages are
+// aligned in a column and wrapped to the width of the terminal, if w is one.
+func (c *Commander) flagUsages(w io.Writer, f *pflag.FlagSet) string {
+	sortFlags := f.SortFlags
+	f.SortFlags = false
+	flags := []*pflag.Flag{}
+	f.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Hidden {
+			flags = append(flags, flag)
+		}
+	})
+	f.SortFlags = sortFlags
+
+	if c.FlagOrder == FlagOrderLexical || (c.FlagOrder == FlagOrderFlagSet && sortFlags) {
+		sort.SliceStable(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	}
+	if c.GroupShorthandFlags {
+		sort.SliceStable(flags, func(i, j int) bool { return hasShorthand(flags[i]) && !hasShorthand(flags[j]) })
+	}
+
+	names := make([]string, len(flags))
+	column := 0
+	for i, flag := range flags {
+		names[i] = flagColumn(flag)
+		if len(names[i]) <= maxFlagColumn && len(names[i]) > column {
+			column = len(names[i])
+		}
+	}
+	column += 3
+
+	width := terminalWidth(w) - column
+	sep := "\n" + strings.Repeat(" ", column)
+	buf := strings.Builder{}
+	for i, flag := range flags {
+		usage := c.flagUsage(flag)
+		if width >= minSynopsisWidth {
+			paragraphs := strings.Split(usage, "\n")
+			for j, p := range paragraphs {
+				paragraphs[j] = wrap(p, width, sep)
+			}
+			usage = strings.Join(paragraphs, sep)
+		} else {
+			usage = strings.ReplaceAll(usage, "\n", sep)
+		}
+
+		buf.WriteString(names[i])
+		if len(names[i])+3 > column {
+			buf.WriteString(sep)
+		} else {
+			buf.WriteString(strings.Repeat(" ", column-len(names[i])))
+		}
+		buf.WriteString(usage)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func hasShorthand(flag *pflag.Flag) bool {
+	return flag.Shorthand != "" && flag.ShorthandDeprecated == ""
+}
+
+// flagColumn returns the name column of flag, e.g. "  -o, --output string".
+func flagColumn(flag *pflag.Flag) string {
+	name := fmt.Sprintf("      --%s", flag.Name)
+	if hasShorthand(flag) {
+		name = fmt.Sprintf("  -%s, --%s", flag.Shorthand, flag.Name)
+	}
+	if varname, _ := pflag.UnquoteUsage(flag); varname != "" {
+		name += " " + varname
+	}
+	if flag.NoOptDefVal == "" {
+		return name
+	}
+
+	switch flag.Value.Type() {
+	case "string":
+		name += fmt.Sprintf("[=%q]", flag.NoOptDefVal)
+	case "bool":
+		if flag.NoOptDefVal != "true" {
+			name += fmt.Sprintf("[=%s]", flag.NoOptDefVal)
+		}
+	case "count":
+		if flag.NoOptDefVal != "+1" {
+			name += fmt.Sprintf("[=%s]", flag.NoOptDefVal)
+		}
+	default:
+		name += fmt.Sprintf("[=%s]", flag.NoOptDefVal)
+	}
+	return name
+}
+
+// flagUsage returns the usage of flag including its default value and
+// deprecation notice.
+func (c *Commander) flagUsage(flag *pflag.Flag) string {
+	_, usage := pflag.UnquoteUsage(flag)
+	if values, ok := flag.Annotations[annotationEnum]; ok {
+		usage += fmt.Sprintf(" (one of: %s)", strings.Join(values, ", "))
+	}
+	switch flag.DefValue {
+	case "", "false", "0", "0s", "[]", "<nil>":
+	default:
+		if flag.Value.Type() == "string" {
+			usage += fmt.Sprintf(" (default %q)", flag.DefValue)
+		} else {
+			usage += fmt.Sprintf(" (default %s)", flag.DefValue)
+		}
+	}
+	if flag.Deprecated != "" {
+		usage += fmt.Sprintf(" (DEPRECATED: %s)", flag.Deprecated)
+	} else if notice, ok := deprecation(c.tr, flag); ok {
+		usage += fmt.Sprintf(" (%s)", notice)
+	}
+	return usage
+}
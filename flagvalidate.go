@@ -0,0 +1,115 @@
+package psubcommands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagValidator validates the value of a flag. The error is reported after
+// the name of the flag, e.g. "must be 1-65535".
+type FlagValidator func(value string) error
+
+// validatedValue wraps the Value of a flag with its validators.
+type validatedValue struct {
+	pflag.Value
+	validators []FlagValidator
+}
+
+// unwrapValue returns the Value wrapped by MarkFlagValidator, if any.
+func unwrapValue(v pflag.Value) pflag.Value {
+	if w, ok := v.(*validatedValue); ok {
+		return w.Value
+	}
+	return v
+}
+
+// MarkFlagValidator attaches validators to the named flag. The validators
+// of all flags set are run after parsing and their errors are reported
+// together before the command is executed.
+func MarkFlagValidator(f *pflag.FlagSet, name string, validators ...FlagValidator) error {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("flag %q does not exist", name)
+	}
+	if v, ok := flag.Value.(*validatedValue); ok {
+		v.validators = append(v.validators, validators...)
+		return nil
+	}
+	flag.Value = &validatedValue{Value: flag.Value, validators: validators}
+	return nil
+}
+
+// Range returns a FlagValidator accepting integers between min and max.
+func Range(min, max int64) FlagValidator {
+	return func(value string) error {
+		n, err := strconv.ParseInt(value, 0, 64)
+		if err != nil || n < min || n > max {
+			return fmt.Errorf("must be %d-%d", min, max)
+		}
+		return nil
+	}
+}
+
+// Match returns a FlagValidator accepting values matching the regular
+// expression pattern. It panics if pattern can't be compiled.
+func Match(pattern string) FlagValidator {
+	re := regexp.MustCompile(pattern)
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+		return nil
+	}
+}
+
+// checkFlagValidators runs the validators of all flags of f which were set
+// and reports all failures at once.
+func (c *Commander) checkFlagValidators(f *pflag.FlagSet) ExitStatus {
+	var failed []string
+	f.VisitAll(func(flag *pflag.Flag) {
+		v, ok := flag.Value.(*validatedValue)
+		if !ok || !flag.Changed {
+			return
+		}
+		values := []string{v.String()}
+		if s, ok := unwrapValue(v).(pflag.SliceValue); ok {
+			values = s.GetSlice()
+		}
+		for _, validate := range v.validators {
+			value, err := validateAll(validate, values)
+			var unknown *UnknownValueError
+			switch {
+			case errors.As(err, &unknown) && isSecret(flag):
+				// Suggestions would reveal how close the secret was.
+				masked := &UnknownValueError{Kind: unknown.Kind, Value: MaskedValue}
+				failed = append(failed, fmt.Sprintf("--%s: %s", flag.Name, masked))
+				return
+			case errors.As(err, &unknown):
+				failed = append(failed, fmt.Sprintf("--%s: %s", flag.Name, err))
+				return
+			case err != nil:
+				failed = append(failed, fmt.Sprintf("--%s %s", flag.Name, maskValue(flag, value, err)))
+				return
+			}
+		}
+	})
+	if len(failed) == 0 {
+		return ExitSuccess
+	}
+	fmt.Fprintln(f.Output(), strings.Join(failed, "; "))
+	return ExitUsageError
+}
+
+func validateAll(validate FlagValidator, values []string) (string, error) {
+	for _, value := range values {
+		if err := validate(value); err != nil {
+			return value, err
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,83 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// DefaultFlushTimeout is used if Commander.FlushTimeout is zero.
+const DefaultFlushTimeout = 5 * time.Second
+
+// flush flushes w if it is buffered. Supported are writers implementing
+// FlushContext(context.Context) error, Flush() error like bufio.Writer or
+// Flush() like http.Flusher.
+func flush(ctx context.Context, w io.Writer) error {
+	var fn func() error
+	switch f := w.(type) {
+	case interface {
+		FlushContext(ctx context.Context) error
+	}:
+		return f.FlushContext(ctx)
+	case interface{ Flush() error }:
+		fn = f.Flush
+	case interface{ Flush() }:
+		fn = func() error { f.Flush(); return nil }
+	default:
+		return nil
+	}
+
+	// Flush may block on a remote writer, give up once ctx is done.
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushOutput flushes the given writers in order, waiting at most
+// FlushTimeout for all of them. Failures are written to Error, so it should
+// be given last.
+func (c *Commander) flushOutput(ctx context.Context, writers ...io.Writer) {
+	timeout := c.FlushTimeout
+	if timeout == 0 {
+		timeout = DefaultFlushTimeout
+	}
+	// Output must be flushed even if ctx was cancelled by a signal.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	// Writers of non-comparable types can't be deduplicated.
+	flushed := map[io.Writer]bool{}
+	for _, w := range writers {
+		comparable := w != nil && reflect.TypeOf(w).Comparable()
+		if w == nil || comparable && flushed[w] {
+			continue
+		}
+		if comparable {
+			flushed[w] = true
+		}
+		if err := flush(ctx, w); err != nil && !sameWriter(w, c.Error) {
+			fmt.Fprintf(c.Error, c.tr("Failed to flush output: %s\n"), err)
+		}
+	}
+}
+
+// sameWriter reports whether a and b are the same writer. Writers of
+// non-comparable types are never the same.
+func sameWriter(a, b io.Writer) bool {
+	t := reflect.TypeOf(a)
+	return t != nil && t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// flushCommandOutput flushes the writers of an OutputRouter.
+func (c *Commander) flushCommandOutput(ctx context.Context, cmd Command) {
+	if _, ok := unwrap(cmd).(OutputRouter); ok {
+		c.flushOutput(ctx, c.helpOutput(cmd), c.errorOutput(cmd), c.Error)
+	}
+}
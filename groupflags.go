@@ -0,0 +1,62 @@
+package psubcommands
+
+import "github.com/spf13/pflag"
+
+// SetGroupFlags attaches flags to a group. setFlags is called for every
+// execution of a command of the group, like Command.SetFlags, and the flags
+// it adds which the command doesn't define itself are added to the flags of
+// the command, so e.g. all database commands accept --dsn. The values can be
+// read from the FlagSet passed to Execute. The group is created if it
+// doesn't exist yet.
+func (c *Commander) SetGroupFlags(group string, setFlags func(f *pflag.FlagSet)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.group(group).flags = setFlags
+	c.help = nil
+}
+
+// SetGroupFlags attaches flags to a group of the DefaultCommander.
+func SetGroupFlags(group string, setFlags func(f *pflag.FlagSet)) {
+	DefaultCommander.SetGroupFlags(group, setFlags)
+}
+
+// Flags attaches flags to the group, see Commander.SetGroupFlags.
+func (b *GroupBuilder) Flags(setFlags func(f *pflag.FlagSet)) *GroupBuilder {
+	if b.err == nil {
+		b.cdr.SetGroupFlags(b.name, setFlags)
+	}
+	return b
+}
+
+// groupFlags returns the function adding the flags of the group cmd is
+// registered to, if any.
+func (c *Commander) groupFlags(cmd Command) func(f *pflag.FlagSet) {
+	e, ok := c.lookupEntry(cmd.Name())
+	if !ok {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return e.group.flags
+}
+
+// mergeGroupFlags adds the flags of the group of cmd to f. Flags already
+// defined by cmd take precedence. The flags are created for every call, so
+// concurrent executions don't share their values.
+func (c *Commander) mergeGroupFlags(cmd Command, f *pflag.FlagSet) {
+	setFlags := c.groupFlags(cmd)
+	if setFlags == nil {
+		return
+	}
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	setFlags(flags)
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if f.Lookup(flag.Name) != nil {
+			return
+		}
+		if flag.Shorthand != "" && f.ShorthandLookup(flag.Shorthand) != nil {
+			flag.Shorthand = ""
+		}
+		f.AddFlag(flag)
+	})
+}
@@ -0,0 +1,160 @@
+package psubcommands
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultHelpPageSize is used if Commander.HelpPageSize is zero.
+const DefaultHelpPageSize = 50
+
+// Glob returns the visible commands whose name matches pattern, using the
+// syntax of path.Match, in the order of the help overview. Only the names
+// are inspected, lazily registered commands are not constructed.
+func (c *Commander) Glob(pattern string) ([]Command, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	matches := []Command{}
+	for _, g := range c.orderedGroups() {
+		for _, cmd := range g.commands {
+			if ok, _ := path.Match(pattern, cmd.Name()); ok {
+				matches = append(matches, cmd)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// Glob returns the commands of the DefaultCommander matching pattern.
+func Glob(pattern string) ([]Command, error) { return DefaultCommander.Glob(pattern) }
+
+// helpPage selects the commands listed by help --filter, --sort and --page.
+// An empty group selects all groups. Sorting by name uses CommandLess if
+// set, e.g. for a locale specific collation, and CommandsByNameFold
+// otherwise.
+type helpPage struct {
+	group  string
+	filter string
+	byName bool
+	page   int
+}
+
+// helpEntry is a command listed by help together with its group.
+type helpEntry struct {
+	group *commandGroup
+	cmd   Command
+}
+
+// helpEntries returns the commands matching p in the order they are listed.
+func (c *Commander) helpEntries(p *helpPage) ([]helpEntry, error) {
+	if _, err := path.Match(p.filter, ""); err != nil {
+		return nil, err
+	}
+
+	entries := []helpEntry{}
+	for _, g := range c.orderedGroups() {
+		if p.group != "" && g.name != p.group {
+			continue
+		}
+		for _, cmd := range g.commands {
+			if ok, _ := path.Match(p.filter, cmd.Name()); ok {
+				entries = append(entries, helpEntry{group: g, cmd: cmd})
+			}
+		}
+	}
+	if p.byName {
+		less := CommandsByNameFold
+		if c.CommandLess != nil {
+			less = c.CommandLess
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return less(entries[i].cmd, entries[j].cmd) })
+	}
+	return entries, nil
+}
+
+// hasGroup reports whether a visible group with the specified name exists.
+// Unlike ExplainGroup it doesn't render the commands of the group.
+func (c *Commander) hasGroup(name string) bool {
+	for _, g := range c.orderedGroups() {
+		if g.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// helpPageSize returns the number of commands listed per page.
+func (c *Commander) helpPageSize() int {
+	switch {
+	case c.HelpPageSize < 0:
+		return math.MaxInt
+	case c.HelpPageSize == 0:
+		return DefaultHelpPageSize
+	}
+	return c.HelpPageSize
+}
+
+// explainPage writes a page of entries to w. Synopses are only requested
+// for the commands on this page.
+func (c *Commander) explainPage(w io.Writer, p *helpPage, entries []helpEntry, pages int) {
+	size := c.helpPageSize()
+	start := (p.page - 1) * size
+	end := min(start+size, len(entries))
+	entries = entries[start:end]
+
+	if p.byName {
+		fmt.Fprint(w, c.tr("Subcommands:\n"))
+		for _, e := range entries {
+			fmt.Fprint(w, overviewLine(w, e.cmd.Name(), c.synopsis(e.cmd)))
+		}
+		fmt.Fprintln(w)
+	} else {
+		for i := 0; i < len(entries); {
+			group := entries[i].group
+			g := &commandGroup{name: group.name, description: group.description}
+			for ; i < len(entries) && entries[i].group == group; i++ {
+				g.commands = append(g.commands, entries[i].cmd)
+			}
+			c.explainGroup(w, g)
+		}
+	}
+
+	if pages > 1 {
+		if p.page < pages {
+			fmt.Fprintf(w, c.tr("Page %d of %d, use --page %d for more.\n"), p.page, pages, p.page+1)
+		} else {
+			fmt.Fprintf(w, c.tr("Page %d of %d\n"), p.page, pages)
+		}
+	}
+}
+
+// listCommands writes the commands selected by p to Output, as requested
+// by help --filter, --sort or --page.
+func (c *Commander) listCommands(f *pflag.FlagSet, p *helpPage) ExitStatus {
+	if p.group != "" && !c.hasGroup(p.group) {
+		fmt.Fprintf(c.Error, c.tr("Group %s not found\n"), p.group)
+		return ExitUsageError
+	}
+	entries, err := c.helpEntries(p)
+	if err != nil {
+		return UsageErrorf(f, c.tr("Invalid --filter %q: %s"), p.filter, err)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(c.Error, c.tr("No subcommands match %s\n"), p.filter)
+		return ExitFailure
+	}
+
+	size := c.helpPageSize()
+	pages := (len(entries)-1)/size + 1
+	if p.page < 1 || p.page > pages {
+		return UsageErrorf(f, c.tr("Page %d out of range, the last page is %d"), p.page, pages)
+	}
+	c.writeHelp(c.Output, func(w io.Writer) { c.explainPage(w, p, entries, pages) })
+	return ExitSuccess
+}
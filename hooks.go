@@ -0,0 +1,74 @@
+package psubcommands
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// CommandEvent describes a single command execution passed to the hooks
+// registered with OnCommandStart and OnCommandEnd.
+type CommandEvent struct {
+	// Name of the executed command.
+	Name string
+
+	// Flags holds the values of all flags set on the command line.
+	// Values of secret flags are masked, see MarkFlagSecret.
+	Flags map[string]string
+
+	// Args holds the positional arguments.
+	Args []string
+
+	// Start is the time the command was started.
+	Start time.Time
+
+	// Duration is the execution time of the command. Only set for OnCommandEnd.
+	Duration time.Duration
+
+	// Status is the ExitStatus returned by the command. Only set for OnCommandEnd.
+	Status ExitStatus
+}
+
+// OnCommandStart registers fn to be called before a command is executed.
+func (c *Commander) OnCommandStart(fn func(ctx context.Context, ev *CommandEvent)) {
+	c.onStart = append(c.onStart, fn)
+}
+
+// OnCommandEnd registers fn to be called after a command was executed.
+func (c *Commander) OnCommandEnd(fn func(ctx context.Context, ev *CommandEvent)) {
+	c.onEnd = append(c.onEnd, fn)
+}
+
+// executeWithHooks executes cmd and calls the registered hooks.
+func (c *Commander) executeWithHooks(ctx context.Context, cmd Command, f *pflag.FlagSet, exec func(context.Context) ExitStatus) ExitStatus {
+	if len(c.onStart) == 0 && len(c.onEnd) == 0 {
+		return exec(ctx)
+	}
+
+	ev := &CommandEvent{
+		Name:  cmd.Name(),
+		Flags: changedFlags(f),
+		Args:  f.Args(),
+		Start: time.Now(),
+	}
+	for _, fn := range c.onStart {
+		fn(ctx, ev)
+	}
+
+	ev.Status = exec(ctx)
+	ev.Duration = time.Since(ev.Start)
+	for _, fn := range c.onEnd {
+		fn(ctx, ev)
+	}
+	return ev.Status
+}
+
+// changedFlags returns the values of all flags of f set on the command line.
+func changedFlags(f *pflag.FlagSet) map[string]string {
+	flags := map[string]string{}
+	f.Visit(func(flag *pflag.Flag) {
+		flags[flag.Name] = FlagValue(flag)
+	})
+	return flags
+}
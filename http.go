@@ -0,0 +1,111 @@
+package psubcommands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ExitStatusHeader is the HTTP trailer holding the ExitStatus of a command
+// executed through ServeHTTP.
+const ExitStatusHeader = "Psubcommands-Exit-Status"
+
+// HTTPRequest is the body of a request to ServeHTTP.
+type HTTPRequest struct {
+	// Args is the command line starting with the subcommand name.
+	Args []string `json:"args"`
+
+	// Env, if not nil, is the environment of the command, see LookupEnv.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// HTTPResponse is returned by ServeHTTP to clients accepting application/json.
+type HTTPResponse struct {
+	Status ExitStatus `json:"status"`
+	Stdout string     `json:"stdout"`
+	Stderr string     `json:"stderr"`
+}
+
+// ServeHTTP executes the command line posted as HTTPRequest and implements
+// http.Handler, allowing automation to drive the Commander without spawning
+// processes. Output and Error of the command are streamed to the client as
+// they are written, the ExitStatus is sent in the ExitStatusHeader trailer.
+// Clients accepting application/json receive an HTTPResponse instead.
+//
+// Top-level flags aren't parsed and Input is empty. Requests are executed
+// one at a time. The Commander doesn't authenticate clients, so the handler
+// should only be served on a local address or behind an authenticating
+// middleware.
+func (c *Commander) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := HTTPRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Args) == 0 {
+		http.Error(w, "invalid request: no subcommand given", http.StatusBadRequest)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		status := c.serve(r, stdout, stderr, &req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&HTTPResponse{Status: status, Stdout: stdout.String(), Stderr: stderr.String()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Trailer", ExitStatusHeader)
+	out := &flushWriter{w: w}
+	out.f, _ = w.(http.Flusher)
+	status := c.serve(r, out, out, &req)
+	w.Header().Set(ExitStatusHeader, fmt.Sprint(int(status)))
+}
+
+// serve executes req with the output redirected to stdout and stderr.
+func (c *Commander) serve(r *http.Request, stdout, stderr io.Writer, req *HTTPRequest) ExitStatus {
+	c.serveMu.Lock()
+	defer c.serveMu.Unlock()
+
+	output, errOutput, input := c.Output, c.Error, c.Input
+	c.Output, c.Error, c.Input = stdout, stderr, strings.NewReader("")
+	defer func() { c.Output, c.Error, c.Input = output, errOutput, input }()
+
+	ctx := r.Context()
+	if req.Env != nil {
+		ctx = WithEnv(ctx, EnvMap(req.Env))
+	}
+	// Arguments may hold secrets, so only the subcommand name is logged.
+	c.debug(ctx, "executing HTTP request", "remote", r.RemoteAddr, "name", req.Args[0])
+	defer c.flushOutput(ctx, c.Output, c.Error)
+	defer c.shutdown(ctx)
+	return c.dispatch(ctx, req.Args)
+}
+
+// flushWriter flushes every write to the client.
+type flushWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	f  http.Flusher
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	n, err := fw.w.Write(p)
+	if fw.f != nil {
+		fw.f.Flush()
+	}
+	return n, err
+}
@@ -0,0 +1,12 @@
+package psubcommands
+
+// tr translates the fmt format string using the Translate hook of the Commander.
+func (c *Commander) tr(format string) string {
+	if c.Translate == nil {
+		return format
+	}
+	return c.Translate(format)
+}
+
+// identity is used as translation if no Commander is available.
+func identity(format string) string { return format }
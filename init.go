@@ -0,0 +1,110 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// WriteShellInit writes the code setting up this program in shell ("bash",
+// "zsh" or "fish") to w: the completion script, ShellAliases and ShellInit.
+// It is meant to be evaluated by the shell, e.g. in ~/.bashrc:
+//
+//	eval "$(tool init bash)"
+func (c *Commander) WriteShellInit(w io.Writer, shell string) error {
+	if err := c.WriteCompletionScript(w, shell); err != nil {
+		return err
+	}
+
+	name := filepath.Base(c.name)
+	aliases := make([]string, 0, len(c.ShellAliases))
+	for alias := range c.ShellAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		line := shellQuote(shell, name+" "+c.ShellAliases[alias])
+		var err error
+		if shell == "fish" {
+			_, err = fmt.Fprintf(w, "alias %s %s\n", alias, line)
+		} else {
+			_, err = fmt.Fprintf(w, "alias %s=%s\n", alias, line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if code := c.ShellInit[shell]; code != "" {
+		if !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+		_, err := io.WriteString(w, code)
+		return err
+	}
+	return nil
+}
+
+// shellQuote quotes s as a single word for shell.
+func shellQuote(shell, s string) string {
+	if shell == "fish" {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+type initCommand Commander
+
+// Name of this command.
+func (*initCommand) Name() string { return "init" }
+
+// Synopsis returns a short description of this command.
+func (i *initCommand) Synopsis() string {
+	return (*Commander)(i).tr("print shell setup code for bash, zsh or fish")
+}
+
+// Usage returns the long description of this command.
+func (i *initCommand) Usage() string {
+	name := filepath.Base(i.name)
+	return fmt.Sprintf((*Commander)(i).tr("Add the following line to your shell's startup file:\n\n  eval \"$(%s init bash)\"\n\nFor fish use:\n\n  %s init fish | source"), name, name)
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*initCommand) SetFlags(*pflag.FlagSet) {}
+
+// Args returns the positional arguments of this command.
+func (*initCommand) Args() ArgSpec {
+	return ArgSpec{Names: []string{"shell"}, Min: 1, Max: 1}
+}
+
+// Complete returns the supported shells.
+func (*initCommand) Complete(name, _ string) []string {
+	if name != "" {
+		return nil
+	}
+	return []string{"bash", "zsh", "fish"}
+}
+
+// Execute executs this command and returns it's ExitStatus.
+func (i *initCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	if err := (*Commander)(i).WriteShellInit(Streams(ctx).Out, f.Arg(0)); err != nil {
+		return UsageErrorf(f, "%s", err)
+	}
+	return ExitSuccess
+}
+
+// RegisterInitCommand registers the "init" command printing shell setup
+// code to the specified group. Like RegisterCompletionCommand it registers
+// the hidden "__complete" command used by the completion scripts.
+func (c *Commander) RegisterInitCommand(group string) {
+	c.Register(group, (*initCommand)(c))
+	c.registerComplete()
+}
+
+// RegisterInitCommand registers the init command on the DefaultCommander.
+func RegisterInitCommand(group string) { DefaultCommander.RegisterInitCommand(group) }
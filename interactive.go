@@ -0,0 +1,53 @@
+package psubcommands
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// NoInputFlag is the name of the flag registered by EnableNoInputFlag.
+const NoInputFlag = "no-input"
+
+// EnableNoInputFlag registers the top-level flag --no-input which disables
+// all interactive features, see Interactive.
+func (c *Commander) EnableNoInputFlag() {
+	c.topFlags.Bool(NoInputFlag, false, c.tr("never prompt for input"))
+}
+
+// EnableNoInputFlag registers the --no-input flag on the DefaultCommander.
+func EnableNoInputFlag() { DefaultCommander.EnableNoInputFlag() }
+
+// noInputEnv returns the environment variable disabling interactive features.
+func (c *Commander) noInputEnv() string {
+	if c.NoInputEnv != "" {
+		return c.NoInputEnv
+	}
+	name := strings.ToUpper(filepath.Base(c.name))
+	return nonEnvChars.ReplaceAllString(name, "_") + "_NO_INPUT"
+}
+
+// interactive reports whether the Commander may ask for input on in.
+func (c *Commander) interactive(in io.Reader, getenv func(string) string) bool {
+	if noInput, _ := c.topFlags.GetBool(NoInputFlag); noInput {
+		return false
+	}
+	if envTrue(getenv(c.noInputEnv())) {
+		return false
+	}
+	return isTerminal(in)
+}
+
+// Interactive reports whether the current command may ask the user for
+// input. It is false if the input isn't a terminal, --no-input was given
+// (see EnableNoInputFlag) or the environment variable NoInputEnv is set.
+// Prompting for missing flags, Confirm, the pager and the command picker
+// respect it.
+func Interactive(ctx context.Context) bool {
+	in := Streams(ctx).In
+	if c := CommanderFromContext(ctx); c != nil {
+		return c.interactive(in, func(key string) string { return Getenv(ctx, key) })
+	}
+	return isTerminal(in)
+}
@@ -0,0 +1,54 @@
+package psubcommands
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+type lazyCommand struct {
+	name     string
+	synopsis string
+	factory  func() Command
+
+	once sync.Once
+	cmd  Command
+}
+
+func (l *lazyCommand) command() Command {
+	l.once.Do(func() { l.cmd = l.factory() })
+	return l.cmd
+}
+
+func (l *lazyCommand) Name() string              { return l.name }
+func (l *lazyCommand) Synopsis() string          { return l.synopsis }
+func (l *lazyCommand) SetFlags(f *pflag.FlagSet) { l.command().SetFlags(f) }
+
+func (l *lazyCommand) Execute(ctx context.Context, f *pflag.FlagSet, args ...interface{}) ExitStatus {
+	return l.command().Execute(ctx, f, args...)
+}
+
+// unwrap returns the actual command behind a lazily registered command.
+func unwrap(cmd Command) Command {
+	if l, ok := cmd.(*lazyCommand); ok {
+		return l.command()
+	}
+	return cmd
+}
+
+// RegisterLazy registers a command which is constructed by factory only when
+// it is executed or its detailed help is requested. The name and synopsis
+// are used for dispatch and in the help overview.
+func (c *Commander) RegisterLazy(group, name, synopsis string, factory func() Command) {
+	c.Register(group, &lazyCommand{
+		name:     name,
+		synopsis: synopsis,
+		factory:  factory,
+	})
+}
+
+// RegisterLazy registers a lazily constructed command on the DefaultCommander.
+func RegisterLazy(group, name, synopsis string, factory func() Command) {
+	DefaultCommander.RegisterLazy(group, name, synopsis, factory)
+}
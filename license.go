@@ -0,0 +1,72 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Dependencies returns the modules the program was built with as
+// "path version" pairs. Replaced modules are reported with their
+// replacement.
+func Dependencies() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	deps := make([]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		deps = append(deps, strings.TrimSpace(dep.Path+" "+dep.Version))
+	}
+	return deps
+}
+
+type licenseCommand Commander
+
+// Name of this command.
+func (*licenseCommand) Name() string { return "license" }
+
+// Synopsis returns a short description of this command.
+func (l *licenseCommand) Synopsis() string {
+	return (*Commander)(l).tr("print license and third-party notices")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*licenseCommand) SetFlags(*pflag.FlagSet) {}
+
+// Execute executs this command and returns it's ExitStatus.
+func (l *licenseCommand) Execute(ctx context.Context, _ *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	c := (*Commander)(l)
+	streams := Streams(ctx)
+	sections := []string{}
+	if c.License != "" {
+		sections = append(sections, strings.TrimRight(c.License, "\n"))
+	}
+	if deps := Dependencies(); len(deps) > 0 {
+		sections = append(sections, c.tr("This program includes the following modules:")+"\n\n  "+strings.Join(deps, "\n  "))
+	}
+	if c.Notices != "" {
+		sections = append(sections, strings.TrimRight(c.Notices, "\n"))
+	}
+	if len(sections) == 0 {
+		fmt.Fprintln(streams.Err, c.tr("No license information available"))
+		return ExitFailure
+	}
+
+	fmt.Fprintln(streams.Out, strings.Join(sections, "\n\n"))
+	return ExitSuccess
+}
+
+// RegisterLicenseCommand registers the "license" command printing License,
+// the modules the program was built with and Notices to the specified group.
+func (c *Commander) RegisterLicenseCommand(group string) { c.Register(group, (*licenseCommand)(c)) }
+
+// RegisterLicenseCommand registers the license command on the DefaultCommander.
+func RegisterLicenseCommand(group string) { DefaultCommander.RegisterLicenseCommand(group) }
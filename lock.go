@@ -0,0 +1,62 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LockNamer may be implemented by a Command which must not run more than
+// once at a time for the same user. Commands returning the same lock name
+// exclude each other, an empty name disables locking.
+type LockNamer interface {
+	LockName() string
+}
+
+// lockDir returns the per-user directory holding the lock files.
+func (c *Commander) lockDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, filepath.Base(c.name), "locks")
+}
+
+// lock acquires the lock of cmd, if any. It returns a function releasing
+// the lock, or ExitLocked if another instance holds the lock.
+func (c *Commander) lock(ctx context.Context, cmd Command) (func(), ExitStatus) {
+	l, ok := cmd.(LockNamer)
+	if !ok || l.LockName() == "" {
+		return func() {}, ExitSuccess
+	}
+
+	dir := c.lockDir()
+	path := filepath.Join(dir, l.LockName()+".lock")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0700); err == nil {
+			file, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(c.Error, c.tr("Failed to create lock file: %s\n"), err)
+		return nil, ExitFailure
+	}
+
+	locked, err := tryLock(file)
+	if err != nil {
+		file.Close()
+		fmt.Fprintf(c.Error, c.tr("Failed to lock %s: %s\n"), path, err)
+		return nil, ExitFailure
+	}
+	if !locked {
+		file.Close()
+		fmt.Fprintf(c.Error, c.tr("Subcommand %s is already running (lock %s)\n"), cmd.Name(), path)
+		return nil, ExitLocked
+	}
+
+	c.debug(ctx, "acquired lock", "command", cmd.Name(), "path", path)
+	// Closing the file releases the lock.
+	return func() { file.Close() }, ExitSuccess
+}
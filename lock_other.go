@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package psubcommands
+
+import "os"
+
+// tryLock doesn't lock on platforms without file locking support.
+func tryLock(*os.File) (bool, error) { return true, nil }
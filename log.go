@@ -0,0 +1,23 @@
+package psubcommands
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// debug logs msg at debug level if a Logger is configured.
+func (c *Commander) debug(ctx context.Context, msg string, args ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.DebugContext(ctx, msg, args...)
+	}
+}
+
+// LoggerFromContext returns the Logger of the Commander executing the current
+// command. If there is none, a logger discarding all records is returned.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if c := CommanderFromContext(ctx); c != nil && c.Logger != nil {
+		return c.Logger
+	}
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,36 @@
+package psubcommands
+
+import (
+	"context"
+
+	"github.com/spf13/pflag"
+)
+
+// ExecuteFunc executes a command. It has the signature of Command.Execute
+// with the command passed explicitly.
+type ExecuteFunc func(ctx context.Context, cmd Command, f *pflag.FlagSet, args ...interface{}) ExitStatus
+
+// Middleware wraps the execution of every command, e.g. to add tracing
+// or to modify the context passed to the command.
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
+// Use adds middlewares wrapping the execution of every command. The first
+// middleware added is the outermost one.
+func (c *Commander) Use(mw ...Middleware) { c.middlewares = append(c.middlewares, mw...) }
+
+// Use adds middlewares to the DefaultCommander.
+func Use(mw ...Middleware) { DefaultCommander.Use(mw...) }
+
+// executeFunc returns the ExecuteFunc calling cmd through all middlewares.
+func (c *Commander) executeFunc() ExecuteFunc {
+	exec := ExecuteFunc(func(ctx context.Context, cmd Command, f *pflag.FlagSet, args ...interface{}) ExitStatus {
+		if r, ok := cmd.(Resulter); ok {
+			return c.executeResult(ctx, r, f, args...)
+		}
+		return cmd.Execute(ctx, f, args...)
+	})
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		exec = c.middlewares[i](exec)
+	}
+	return exec
+}
@@ -0,0 +1,67 @@
+package psubcommands
+
+import "strings"
+
+// namespace returns the namespace of the command name, e.g. "db" for
+// "db:migrate", or an empty string if NamespaceSeparator isn't set or name
+// has no namespace.
+func (c *Commander) namespace(name string) string {
+	if c.NamespaceSeparator == "" {
+		return ""
+	}
+	if i := strings.Index(name, c.NamespaceSeparator); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// splitNamespaces splits cmds into commands without namespace and the
+// commands of each namespace, in order of the namespaces' first command.
+func (c *Commander) splitNamespaces(cmds []Command) ([]Command, []string, map[string][]Command) {
+	plain := []Command{}
+	namespaces := []string{}
+	byNamespace := map[string][]Command{}
+	for _, cmd := range cmds {
+		ns := c.namespace(cmd.Name())
+		if ns == "" {
+			plain = append(plain, cmd)
+			continue
+		}
+		if _, ok := byNamespace[ns]; !ok {
+			namespaces = append(namespaces, ns)
+		}
+		byNamespace[ns] = append(byNamespace[ns], cmd)
+	}
+	return plain, namespaces, byNamespace
+}
+
+// collapseNamespaces replaces the names of all commands of a namespace by
+// the namespace followed by the separator, unless prefix already selects a
+// namespace or only a single command of the namespace matches. Once prefix
+// selects a namespace, e.g. "db:", only the commands of that namespace
+// match prefix and are completed.
+func (c *Commander) collapseNamespaces(names []string, prefix string) []string {
+	if c.NamespaceSeparator == "" || strings.Contains(prefix, c.NamespaceSeparator) {
+		return names
+	}
+
+	count := map[string]int{}
+	for _, name := range names {
+		count[c.namespace(name)]++
+	}
+
+	collapsed := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		ns := c.namespace(name)
+		if ns == "" || count[ns] == 1 {
+			collapsed = append(collapsed, name)
+			continue
+		}
+		if !seen[ns] {
+			seen[ns] = true
+			collapsed = append(collapsed, ns+c.NamespaceSeparator)
+		}
+	}
+	return collapsed
+}
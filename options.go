@@ -0,0 +1,59 @@
+package psubcommands
+
+import (
+	"io"
+
+	"github.com/spf13/pflag"
+)
+
+// Option configures a Commander created by New.
+type Option func(c *Commander)
+
+// New creates a new Commander like NewCommander, but is configured through
+// typed options which are applied in order.
+func New(name string, opts ...Option) *Commander {
+	cdr := NewCommander(name)
+	for _, opt := range opts {
+		opt(cdr)
+	}
+	return cdr
+}
+
+// WithOutput sets the writer for regular output, see Commander.Output.
+func WithOutput(w io.Writer) Option { return func(c *Commander) { c.Output = w } }
+
+// WithErrorWriter sets the writer for error messages, see Commander.Error.
+func WithErrorWriter(w io.Writer) Option { return func(c *Commander) { c.Error = w } }
+
+// WithInput sets the reader for interactive input, see Commander.Input.
+func WithInput(r io.Reader) Option { return func(c *Commander) { c.Input = r } }
+
+// WithFlagSet uses f for the top-level flags instead of a new FlagSet. The
+// error handling of f is kept unless WithErrorHandling follows.
+func WithFlagSet(f *pflag.FlagSet) Option {
+	return func(c *Commander) {
+		c.topFlags = f
+		c.ownFlags = false
+		c.onError = flagSetErrorHandling(f)
+		f.Usage = c.usage
+	}
+}
+
+// WithErrorHandling sets how the top-level FlagSet handles parse errors.
+// The default is pflag.ExitOnError.
+func WithErrorHandling(h pflag.ErrorHandling) Option {
+	return func(c *Commander) {
+		c.topFlags.Init(c.topFlags.Name(), h)
+		c.onError = h
+		c.ownFlags = true
+	}
+}
+
+// WithGroups registers the commands of each group, in order of the group names.
+func WithGroups(groups map[string][]Command) Option {
+	return func(c *Commander) {
+		for _, group := range sortedKeys(groups) {
+			c.Register(group, groups[group]...)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package psubcommands
+
+import (
+	"sort"
+	"strings"
+)
+
+// GroupsByName orders groups alphabetically. It can be used as Commander.GroupLess.
+func GroupsByName(a, b string) bool { return a < b }
+
+// CommandsByName orders commands alphabetically. It can be used as Commander.CommandLess.
+func CommandsByName(a, b Command) bool { return a.Name() < b.Name() }
+
+// CommandsByNameFold orders commands alphabetically ignoring case, so that
+// names differing only in case are listed next to each other. It compares
+// bytes and doesn't follow the collation rules of any locale.
+func CommandsByNameFold(a, b Command) bool {
+	if x, y := strings.ToLower(a.Name()), strings.ToLower(b.Name()); x != y {
+		return x < y
+	}
+	return a.Name() < b.Name()
+}
+
+// orderedGroups returns the visible groups and their commands in the order
+// they should be presented to the user.
+func (c *Commander) orderedGroups() []*commandGroup {
+	groups := []*commandGroup{}
+	for _, g := range c.groups() {
+		if g.hidden {
+			continue
+		}
+
+		g.commands = c.visibleCommands(g.commands)
+		if c.CommandLess != nil {
+			sort.SliceStable(g.commands, func(i, j int) bool { return c.CommandLess(g.commands[i], g.commands[j]) })
+		}
+		groups = append(groups, g)
+	}
+
+	pinned := func(name string) int {
+		for i, p := range c.PinnedGroups {
+			if p == name {
+				return i
+			}
+		}
+		return len(c.PinnedGroups)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		pi, pj := pinned(groups[i].name), pinned(groups[j].name)
+		if pi != pj {
+			return pi < pj
+		}
+		if c.GroupLess != nil {
+			return c.GroupLess(groups[i].name, groups[j].name)
+		}
+		return false
+	})
+
+	return groups
+}
+
+// groups returns a snapshot of the registered groups and their commands
+// which stays unaffected by concurrent registrations.
+func (c *Commander) groups() []*commandGroup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	groups := make([]*commandGroup, len(c.commands))
+	for i, g := range c.commands {
+		group := *g
+		group.commands = append([]Command{}, g.commands...)
+		groups[i] = &group
+	}
+	return groups
+}
+
+// sortedKeys returns the keys of m in alphabetical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,242 @@
+package psubcommands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFlag is the name of the flag added to commands implementing OutputFormatter.
+const OutputFlag = "output"
+
+// DefaultOutputFormat is the output format used if --output isn't given.
+const DefaultOutputFormat = "table"
+
+// Renderer writes data to w in a specific output format.
+type Renderer func(w io.Writer, data interface{}) error
+
+// renderers holds the Renderer of every output format, guarded by
+// renderersMu as renderers may be registered while commands execute.
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		"json":  renderJSON,
+		"yaml":  renderYAML,
+		"table": renderTable,
+	}
+)
+
+// RegisterRenderer registers a Renderer for an output format, replacing
+// any existing Renderer for that format.
+func RegisterRenderer(format string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[format] = r
+}
+
+// renderer returns the Renderer registered for format.
+func renderer(format string) (Renderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	r, ok := renderers[format]
+	return r, ok
+}
+
+// rendererFormats returns the sorted formats with a registered Renderer.
+func rendererFormats() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	return sortedKeys(renderers)
+}
+
+// OutputFormatter may be implemented by a Command producing structured output.
+// The Commander adds a -o/--output flag to such commands, validates its value
+// and makes it available through OutputFormat and Render.
+type OutputFormatter interface {
+	// OutputFormats returns the supported formats. If empty all formats
+	// with a registered Renderer are supported.
+	OutputFormats() []string
+}
+
+// outputFormats returns the formats supported by cmd or nil if cmd doesn't
+// produce structured output.
+func outputFormats(cmd Command) []string {
+	o, ok := unwrap(cmd).(OutputFormatter)
+	if !ok {
+		if _, ok := unwrap(cmd).(Resulter); ok {
+			return rendererFormats()
+		}
+		return nil
+	}
+	if formats := o.OutputFormats(); len(formats) > 0 {
+		return formats
+	}
+	return rendererFormats()
+}
+
+// addOutputFlag adds the --output flag to f if cmd produces structured output.
+func (c *Commander) addOutputFlag(cmd Command, f *pflag.FlagSet) {
+	formats := outputFormats(cmd)
+	if formats == nil || f.Lookup(OutputFlag) != nil {
+		return
+	}
+	def := DefaultOutputFormat
+	if !contains(formats, def) {
+		def = formats[0]
+	}
+	f.StringP(OutputFlag, "o", def, fmt.Sprintf(c.tr("output format (%s)"), strings.Join(formats, ", ")))
+}
+
+// checkOutputFormat validates the --output flag of f and stores it in ctx.
+func (c *Commander) checkOutputFormat(ctx context.Context, cmd Command, f *pflag.FlagSet) (context.Context, ExitStatus) {
+	formats := outputFormats(cmd)
+	if formats == nil {
+		return ctx, ExitSuccess
+	}
+
+	format, _ := f.GetString(OutputFlag)
+	if !contains(formats, format) {
+		fmt.Fprintf(f.Output(), c.tr("Invalid output format %q, must be one of: %s\n"), format, strings.Join(formats, ", "))
+		return ctx, ExitUsageError
+	}
+	return context.WithValue(ctx, outputFormatKey, format), ExitSuccess
+}
+
+// OutputFormat returns the output format selected for the current command.
+// It returns DefaultOutputFormat if the command doesn't implement OutputFormatter.
+func OutputFormat(ctx context.Context) string {
+	if format, ok := ctx.Value(outputFormatKey).(string); ok {
+		return format
+	}
+	return DefaultOutputFormat
+}
+
+// Render writes data to the Out stream of the current command using the
+// Renderer registered for format. Use OutputFormat(ctx) to honor --output.
+func Render(ctx context.Context, format string, data interface{}) error {
+	r, ok := renderer(format)
+	if !ok {
+		return fmt.Errorf("psubcommands: no renderer for output format %q", format)
+	}
+	return r(Streams(ctx).Out, data)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func renderJSON(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+func renderYAML(w io.Writer, data interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// renderTable renders slices of structs as table with one row per element,
+// structs and maps as key value pairs and everything else as is.
+func renderTable(w io.Writer, data interface{}) error {
+	var t *Table
+
+	v := reflect.Indirect(reflect.ValueOf(data))
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if elem := indirectType(v.Type().Elem()); elem.Kind() == reflect.Struct {
+			fields := tableFields(elem)
+			header := make([]string, len(fields))
+			for i, field := range fields {
+				header[i] = strings.ToUpper(field.name)
+			}
+			t = newTable(w, os.LookupEnv, header)
+			for i := 0; i < v.Len(); i++ {
+				row := reflect.Indirect(v.Index(i))
+				cells := make([]interface{}, len(fields))
+				for j, field := range fields {
+					if row.IsValid() {
+						cells[j] = row.Field(field.index).Interface()
+					} else {
+						cells[j] = ""
+					}
+				}
+				t.Append(cells...)
+			}
+		} else {
+			t = newTable(w, os.LookupEnv, nil)
+			for i := 0; i < v.Len(); i++ {
+				t.Append(v.Index(i).Interface())
+			}
+		}
+	case reflect.Struct:
+		t = newTable(w, os.LookupEnv, nil)
+		for _, field := range tableFields(v.Type()) {
+			t.Append(field.name+":", v.Field(field.index).Interface())
+		}
+	case reflect.Map:
+		t = newTable(w, os.LookupEnv, nil)
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			t.Append(fmt.Sprint(key.Interface())+":", v.MapIndex(key).Interface())
+		}
+	case reflect.Invalid:
+		return nil
+	default:
+		t = newTable(w, os.LookupEnv, nil).Append(v.Interface())
+	}
+
+	return t.Render()
+}
+
+type tableField struct {
+	name  string
+	index int
+}
+
+// tableFields returns the exported fields of t. The name can be changed with
+// a `table:"name"` tag, fields tagged with `table:"-"` are skipped.
+func tableFields(t reflect.Type) []tableField {
+	fields := []tableField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("table"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		fields = append(fields, tableField{name: name, index: i})
+	}
+	return fields
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
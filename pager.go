@@ -0,0 +1,60 @@
+package psubcommands
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// DefaultPager is used if Commander.Pager is enabled and $PAGER isn't set.
+const DefaultPager = "less -FRX"
+
+// writeHelp renders help output to out. If Pager is enabled, input is
+// interactive, out is a terminal and the help doesn't fit on the screen, it
+// is shown in a pager.
+func (c *Commander) writeHelp(out io.Writer, render func(w io.Writer)) {
+	if !c.Pager || !c.interactive(c.Input, c.getenv) {
+		render(out)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	render(buf)
+
+	file, ok := out.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		out.Write(buf.Bytes())
+		return
+	}
+	if _, height, err := term.GetSize(int(file.Fd())); err != nil || bytes.Count(buf.Bytes(), []byte("\n")) < height {
+		out.Write(buf.Bytes())
+		return
+	}
+
+	pager := c.getenv("PAGER")
+	if pager == "" {
+		pager = DefaultPager
+	}
+	args := strings.Fields(pager)
+	if len(args) == 0 {
+		out.Write(buf.Bytes())
+		return
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(buf.Bytes())
+	cmd.Stdout = file
+	cmd.Stderr = c.Error
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			// The pager couldn't be started at all.
+			out.Write(buf.Bytes())
+		}
+	}
+}
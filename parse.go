@@ -0,0 +1,143 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// parseTopFlags parses argv into the top-level FlagSet. Parse failures are
+// reported as ExitUsageError, or handled as configured by the Commander's
+// pflag.ErrorHandling.
+func (c *Commander) parseTopFlags(ctx context.Context, argv []string) (ExitStatus, bool) {
+	if !c.ownFlags {
+		return c.parseUserFlags(ctx, argv)
+	}
+
+	// Parse with ContinueOnError so errors are reported the same way as
+	// errors of subcommand flags.
+	usage := c.topFlags.Usage
+	c.topFlags.Usage = func() {}
+	c.topFlags.Init(c.topFlags.Name(), pflag.ContinueOnError)
+	err := c.topFlags.Parse(argv)
+	c.topFlags.Init(c.topFlags.Name(), c.onError)
+	c.topFlags.Usage = usage
+
+	switch {
+	case errors.Is(err, pflag.ErrHelp):
+		c.helpFormat, c.helpRequested = helpFormat(argv), true
+		usage()
+		c.helpFormat, c.helpRequested = "", false
+		if c.onError == pflag.ExitOnError {
+			os.Exit(0)
+		}
+		return ExitSuccess, false
+	case err != nil:
+		c.debug(ctx, "parsing top-level flags failed", "error", maskError(err))
+		fmt.Fprintln(c.Error, maskError(err))
+		fmt.Fprint(c.Error, c.usageHint(nil))
+		switch c.onError {
+		case pflag.ExitOnError:
+			os.Exit(int(ExitUsageError))
+		case pflag.PanicOnError:
+			panic(err)
+		}
+		return ExitUsageError, false
+	}
+	return ExitSuccess, true
+}
+
+// parseUserFlags parses argv into a top-level FlagSet supplied by the
+// caller, keeping its error handling. Parse only returns errors for
+// pflag.ContinueOnError, after printing the usage for ErrHelp.
+func (c *Commander) parseUserFlags(ctx context.Context, argv []string) (ExitStatus, bool) {
+	// pflag prints the usage itself, for help and for errors unless
+	// ContinueOnError is used.
+	c.helpFormat, c.helpRequested = helpFormat(argv), wantsHelp(argv)
+	err := c.topFlags.Parse(argv)
+	c.helpFormat, c.helpRequested = "", false
+
+	switch {
+	case errors.Is(err, pflag.ErrHelp):
+		return ExitSuccess, false
+	case err != nil:
+		c.debug(ctx, "parsing top-level flags failed", "error", maskError(err))
+		fmt.Fprintln(c.Error, maskError(err))
+		fmt.Fprint(c.Error, c.usageHint(nil))
+		return ExitUsageError, false
+	}
+	return ExitSuccess, true
+}
+
+// wantsHelp reports whether argv requests the help with -h or --help.
+func wantsHelp(argv []string) bool {
+	for _, arg := range argv {
+		if arg == "--" {
+			break
+		}
+		if arg == "-h" || arg == "--help" || strings.HasPrefix(arg, "--help=") {
+			return true
+		}
+	}
+	return false
+}
+
+// flagSetErrorHandling returns the error handling f was created with. pflag
+// doesn't export it, ExitOnError is assumed if it can't be determined.
+func flagSetErrorHandling(f *pflag.FlagSet) pflag.ErrorHandling {
+	v := reflect.ValueOf(f).Elem().FieldByName("errorHandling")
+	if !v.IsValid() || !v.CanInt() {
+		return pflag.ExitOnError
+	}
+	return pflag.ErrorHandling(v.Int())
+}
+
+// mergeGlobalFlags adds all top-level flags not shadowed by a flag of f to f.
+// Values are shared, so parsing f sets the top-level flags.
+func (c *Commander) mergeGlobalFlags(f *pflag.FlagSet) {
+	c.topFlags.VisitAll(func(flag *pflag.Flag) {
+		if f.Lookup(flag.Name) != nil {
+			return
+		}
+		if flag.Shorthand != "" && f.ShorthandLookup(flag.Shorthand) != nil {
+			cp := *flag
+			cp.Shorthand = ""
+			flag = &cp
+		}
+		f.AddFlag(flag)
+	})
+}
+
+// parseFlags parses argv into the FlagSet f of cmd. It returns false and the
+// ExitStatus to return if the command must not be executed, e.g. because
+// the user requested help with -h or --help.
+func (c *Commander) parseFlags(ctx context.Context, cmd Command, f *pflag.FlagSet, argv []string) (ExitStatus, bool) {
+	usage := f.Usage
+	f.Usage = func() {}
+	err := f.Parse(argv)
+	f.Usage = usage
+
+	switch {
+	case errors.Is(err, pflag.ErrHelp) && helpFormat(argv) == HelpFormatJSON:
+		c.writeJSONHelp(c.helpOutput(cmd), c.exportCommand(cmd))
+		return ExitSuccess, false
+	case errors.Is(err, pflag.ErrHelp):
+		c.writeHelp(c.helpOutput(cmd), func(w io.Writer) { c.ExplainCommand(w, cmd) })
+		return ExitSuccess, false
+	case err != nil:
+		c.debug(ctx, "parsing subcommand flags failed", "command", cmd.Name(), "error", maskError(err))
+		fmt.Fprintln(f.Output(), maskError(err))
+		if hint := c.misplacedFlagHint(cmd, err); hint != "" {
+			fmt.Fprintln(f.Output(), hint)
+		}
+		fmt.Fprint(f.Output(), c.usageHint(cmd))
+		return ExitUsageError, false
+	}
+	return ExitSuccess, true
+}
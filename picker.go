@@ -0,0 +1,78 @@
+package psubcommands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// isTerminal reports whether v is an *os.File connected to a terminal.
+func isTerminal(v interface{}) bool {
+	file, ok := v.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// fuzzyMatch reports whether all characters of pattern appear in s in order,
+// ignoring case.
+func fuzzyMatch(s, pattern string) bool {
+	s, pattern = strings.ToLower(s), strings.ToLower(pattern)
+	for _, r := range pattern {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// pick lets the user select a visible command interactively. The user either
+// enters the number of a command or a pattern to narrow down the list.
+func (c *Commander) pick() (Command, bool) {
+	all := []Command{}
+	for _, g := range c.orderedGroups() {
+		all = append(all, g.commands...)
+	}
+
+	r := bufio.NewReader(c.Input)
+	candidates := all
+	for {
+		for i, cmd := range candidates {
+			fmt.Fprintf(c.Output, "%3d) %-15s    %s\n", i+1, cmd.Name(), cmd.Synopsis())
+		}
+		fmt.Fprint(c.Output, c.tr("Select a command (number or search, empty to cancel): "))
+
+		line, err := r.ReadString('\n')
+		input := strings.TrimSpace(line)
+		if input == "" {
+			return nil, false
+		}
+
+		if n, convErr := strconv.Atoi(input); convErr == nil && n >= 1 && n <= len(candidates) {
+			return candidates[n-1], true
+		}
+
+		matches := []Command{}
+		for _, cmd := range all {
+			if fuzzyMatch(cmd.Name(), input) || fuzzyMatch(cmd.Synopsis(), input) {
+				matches = append(matches, cmd)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			fmt.Fprintf(c.Output, c.tr("No command matches %q\n"), input)
+		case 1:
+			return matches[0], true
+		default:
+			candidates = matches
+		}
+
+		if err != nil {
+			return nil, false
+		}
+	}
+}
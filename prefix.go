@@ -0,0 +1,44 @@
+package psubcommands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolve returns the command which should be executed for name.
+// If PrefixMatching is enabled a unique prefix of a visible command's
+// name resolves to that command. An ambiguous prefix is reported to Error.
+func (c *Commander) resolve(name string) (Command, bool) {
+	if cmd, ok := c.Lookup(name); ok {
+		return cmd, true
+	}
+	if !c.PrefixMatching || name == "" {
+		return nil, false
+	}
+
+	matches := []Command{}
+	for _, g := range c.groups() {
+		if g.hidden {
+			continue
+		}
+		for _, cmd := range g.commands {
+			if strings.HasPrefix(cmd.Name(), name) {
+				matches = append(matches, cmd)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, false
+	case 1:
+		return matches[0], true
+	}
+
+	names := make([]string, len(matches))
+	for i, cmd := range matches {
+		names[i] = cmd.Name()
+	}
+	fmt.Fprintf(c.Error, c.tr("Subcommand %s is ambiguous, could be one of: %s\n"), name, strings.Join(names, ", "))
+	return nil, false
+}
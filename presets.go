@@ -0,0 +1,144 @@
+package psubcommands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// PresetFlag is the name of the flag added to commands having presets.
+const PresetFlag = "preset"
+
+// presetSection is the prefix of rc file sections defining presets, e.g.
+// "[preset deploy staging]".
+const presetSection = "preset "
+
+// RegisterPreset registers a named set of flag values for the command name,
+// applied with --preset. Flags given on the command line take precedence
+// over the preset, the preset over the rc file. Presets can also be defined
+// in the rc file, see EnableRCFile, in sections like
+//
+//	[preset deploy staging]
+//	region = eu-central-1
+//	replicas = 2
+func (c *Commander) RegisterPreset(command, name string, values map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.presets == nil {
+		c.presets = map[string]map[string]map[string]string{}
+	}
+	if c.presets[command] == nil {
+		c.presets[command] = map[string]map[string]string{}
+	}
+	c.presets[command][name] = copyMap(values)
+}
+
+// RegisterPreset registers a preset on the DefaultCommander.
+func RegisterPreset(command, name string, values map[string]string) {
+	DefaultCommander.RegisterPreset(command, name, values)
+}
+
+// Presets returns the presets of the command name, both registered and read
+// from the rc file, by preset name.
+func (c *Commander) Presets(name string) (map[string]map[string]string, error) {
+	presets := map[string]map[string]string{}
+	c.mu.RLock()
+	for preset, values := range c.presets[name] {
+		presets[preset] = values
+	}
+	c.mu.RUnlock()
+
+	rc, err := c.loadRC()
+	prefix := presetSection + name + " "
+	for section, values := range rc {
+		if strings.HasPrefix(section, prefix) {
+			presets[strings.TrimSpace(strings.TrimPrefix(section, prefix))] = values
+		}
+	}
+	return presets, err
+}
+
+// addPresetFlag adds the --preset flag to f if cmd has presets.
+func (c *Commander) addPresetFlag(cmd Command, f *pflag.FlagSet) {
+	presets, _ := c.Presets(cmd.Name())
+	if len(presets) == 0 || f.Lookup(PresetFlag) != nil {
+		return
+	}
+	f.String(PresetFlag, "", fmt.Sprintf(c.tr("apply a preset of flag values (%s)"), strings.Join(sortedKeys(presets), ", ")))
+}
+
+// applyPreset applies the preset selected with --preset, see setPreset.
+func (c *Commander) applyPreset(cmd Command, f *pflag.FlagSet) ExitStatus {
+	if _, err := c.setPreset(cmd, f); err != nil {
+		var unknown *UnknownValueError
+		if errors.As(err, &unknown) {
+			fmt.Fprintf(f.Output(), "%s\n", err)
+		} else {
+			fmt.Fprintln(c.Error, err)
+		}
+		return ExitUsageError
+	}
+	return ExitSuccess
+}
+
+// setPreset sets all flags of f not given on the command line to the values
+// of the preset selected by --preset and returns the names of the flags it
+// set.
+func (c *Commander) setPreset(cmd Command, f *pflag.FlagSet) ([]string, error) {
+	if f.Lookup(PresetFlag) == nil {
+		return nil, nil
+	}
+	name, _ := f.GetString(PresetFlag)
+	if name == "" {
+		return nil, nil
+	}
+
+	presets, err := c.Presets(cmd.Name())
+	if err != nil {
+		return nil, fmt.Errorf(c.tr("Failed to read rc file: %s"), err)
+	}
+	values, ok := presets[name]
+	if !ok {
+		return nil, CheckValue(c.tr("preset"), name, sortedKeys(presets))
+	}
+
+	set := []string{}
+	for _, key := range sortedKeys(values) {
+		flag := f.Lookup(key)
+		if flag == nil {
+			return nil, fmt.Errorf(c.tr("Unknown flag --%s in preset %s"), key, name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := f.Set(key, values[key]); err != nil {
+			return nil, fmt.Errorf(c.tr("Invalid value for --%s in preset %s: %s"), key, name, maskValue(flag, values[key], err))
+		}
+		set = append(set, key)
+	}
+	return set, nil
+}
+
+// explainPresets writes the presets of cmd to w.
+func (c *Commander) explainPresets(w io.Writer, cmd Command) {
+	presets, err := c.Presets(cmd.Name())
+	if err != nil || len(presets) == 0 {
+		return
+	}
+	f := c.commandFlags(cmd)
+	fmt.Fprint(w, c.tr("\nPresets:\n"))
+	for _, name := range sortedKeys(presets) {
+		values := []string{}
+		for _, key := range sortedKeys(presets[name]) {
+			value := presets[name][key]
+			if flag := f.Lookup(key); flag != nil && isSecret(flag) {
+				value = MaskedValue
+			}
+			values = append(values, fmt.Sprintf("--%s=%s", key, value))
+		}
+		fmt.Fprintf(w, "  %s: %s\n", name, strings.Join(values, " "))
+	}
+}
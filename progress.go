@@ -0,0 +1,183 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type progressMode int
+
+const (
+	progressSilent progressMode = iota
+	progressPlain
+	progressTerminal
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+const (
+	// spinnerInterval is the interval at which a Progress on a terminal is redrawn.
+	spinnerInterval = 100 * time.Millisecond
+	// progressLogInterval limits how often a Progress not on a terminal
+	// logs its state.
+	progressLogInterval = time.Second
+)
+
+// Progress reports the progress of a long running operation on the Err
+// stream. On a terminal it shows an animated spinner or counter in a single
+// line, otherwise it degrades to occasional log lines. Progress is safe for
+// concurrent use.
+type Progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	mode    progressMode
+	message string
+	current int
+	total   int
+	frame   int
+	logged  time.Time
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// Progress starts reporting the progress of an operation described by
+// message. If total is positive the progress is shown as a counter,
+// otherwise as a spinner. Done must be called when the operation finished.
+func (s *IOStreams) Progress(message string, total int) *Progress {
+	mode := progressPlain
+	if isTerminal(s.Err) && enableVirtualTerminal(s.Err) {
+		mode = progressTerminal
+	}
+	return startProgress(s.Err, mode, message, total)
+}
+
+// StartProgress starts a Progress on the Streams of the current command.
+// It is silent if --quiet was given and logs plain lines if the command
+// produces machine-readable output, see OutputFormat.
+func StartProgress(ctx context.Context, message string, total int) *Progress {
+	s := Streams(ctx)
+	switch {
+	case Verbosity(ctx) < 0:
+		return startProgress(s.Err, progressSilent, message, total)
+	case OutputFormat(ctx) != DefaultOutputFormat:
+		return startProgress(s.Err, progressPlain, message, total)
+	}
+	return s.Progress(message, total)
+}
+
+func startProgress(w io.Writer, mode progressMode, message string, total int) *Progress {
+	p := &Progress{w: w, mode: mode, message: message, total: total}
+	switch mode {
+	case progressPlain:
+		fmt.Fprintf(w, "%s...\n", message)
+		p.logged = time.Now()
+	case progressTerminal:
+		p.stop, p.stopped = make(chan struct{}), make(chan struct{})
+		p.draw()
+		go p.animate()
+	}
+	return p
+}
+
+// Add advances the progress by n.
+func (p *Progress) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current += n
+	p.update()
+}
+
+// Set sets the progress to current.
+func (p *Progress) Set(current int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = current
+	p.update()
+}
+
+// SetMessage replaces the description of the operation.
+func (p *Progress) SetMessage(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.message = message
+	p.update()
+}
+
+// Done stops the progress and prints message, if not empty, as the result
+// of the operation. Later calls do nothing.
+func (p *Progress) Done(message string) {
+	p.once.Do(func() { p.done(message) })
+}
+
+func (p *Progress) done(message string) {
+	if p.mode == progressTerminal {
+		close(p.stop)
+		<-p.stopped
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch p.mode {
+	case progressTerminal:
+		fmt.Fprint(p.w, "\r\x1b[K")
+		fallthrough
+	case progressPlain:
+		if message != "" {
+			fmt.Fprintln(p.w, message)
+		}
+	}
+}
+
+// update reports a changed state. p.mu must be held.
+func (p *Progress) update() {
+	switch p.mode {
+	case progressTerminal:
+		p.draw()
+	case progressPlain:
+		if counter := p.counter(); counter != "" && time.Since(p.logged) >= progressLogInterval {
+			fmt.Fprintf(p.w, "%s: %s\n", p.message, counter)
+			p.logged = time.Now()
+		}
+	}
+}
+
+func (p *Progress) animate() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame++
+			p.draw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// draw redraws the progress line. p.mu must be held.
+func (p *Progress) draw() {
+	line := fmt.Sprintf("%s %s", spinnerFrames[p.frame%len(spinnerFrames)], p.message)
+	if counter := p.counter(); counter != "" {
+		line += " " + counter
+	}
+	fmt.Fprintf(p.w, "\r\x1b[K%s", line)
+}
+
+// counter formats the current state, e.g. "42/100 (42%)".
+func (p *Progress) counter() string {
+	switch {
+	case p.total > 0:
+		return fmt.Sprintf("%d/%d (%d%%)", p.current, p.total, p.current*100/p.total)
+	case p.current > 0:
+		return fmt.Sprint(p.current)
+	}
+	return ""
+}
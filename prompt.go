@@ -0,0 +1,98 @@
+package psubcommands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// checkRequired makes sure all required flags of f are set. If PromptMissing
+// is enabled and input is interactive the user is asked for every missing
+// value, otherwise an error is printed and ExitUsageError returned.
+func (c *Commander) checkRequired(f *pflag.FlagSet) ExitStatus {
+	missing := missingFlags(f)
+	if len(missing) == 0 {
+		return ExitSuccess
+	}
+
+	if !c.PromptMissing || !c.interactive(c.Input, c.getenv) {
+		names := make([]string, len(missing))
+		for i, flag := range missing {
+			names[i] = "--" + flag.Name
+		}
+		fmt.Fprintf(f.Output(), c.tr("Required flag(s) %s not set\n"), strings.Join(names, ", "))
+		return ExitUsageError
+	}
+
+	r := bufio.NewReader(c.Input)
+	for _, flag := range missing {
+		for {
+			value, err := c.prompt(r, flag)
+			if err != nil {
+				fmt.Fprintf(c.Error, c.tr("\nFailed to read value for --%s: %s\n"), flag.Name, err)
+				return ExitUsageError
+			}
+			if value == "" {
+				continue
+			}
+			if err := f.Set(flag.Name, value); err != nil {
+				fmt.Fprintf(c.Error, c.tr("Invalid value for --%s: %s\n"), flag.Name, maskValue(flag, value, err))
+				continue
+			}
+			break
+		}
+	}
+
+	return ExitSuccess
+}
+
+// checkEnums makes sure all flags of f restricted with MarkFlagEnum which
+// were set have an allowed value. Defaults are always accepted, e.g. an
+// empty string meaning none was chosen.
+func (c *Commander) checkEnums(f *pflag.FlagSet) ExitStatus {
+	status := ExitSuccess
+	f.Visit(func(flag *pflag.Flag) {
+		if status != ExitSuccess {
+			return
+		}
+		if value, ok := invalidEnum(flag); ok {
+			if isSecret(flag) && value != "" {
+				value = MaskedValue
+			}
+			allowed := flag.Annotations[annotationEnum]
+			fmt.Fprintf(f.Output(), c.tr("Invalid value %q for --%s, expected one of: %s\n"), value, flag.Name, strings.Join(allowed, ", "))
+			if suggestions := Suggest(value, allowed); len(suggestions) > 0 && !isSecret(flag) {
+				fmt.Fprintf(f.Output(), c.tr("Did you mean %q?\n"), suggestions[0])
+			}
+			status = ExitUsageError
+		}
+	})
+	return status
+}
+
+func (c *Commander) prompt(r *bufio.Reader, flag *pflag.Flag) (string, error) {
+	if flag.Usage != "" {
+		fmt.Fprintf(c.Output, c.tr("%s (--%s): "), flag.Usage, flag.Name)
+	} else {
+		fmt.Fprintf(c.Output, c.tr("--%s: "), flag.Name)
+	}
+
+	if isSecret(flag) {
+		if file, ok := c.Input.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+			value, err := term.ReadPassword(int(file.Fd()))
+			fmt.Fprintln(c.Output)
+			return string(value), err
+		}
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,54 @@
+package psubcommands
+
+import (
+	"context"
+	"reflect"
+)
+
+// Provide makes value available to all subcommands of this Commander.
+// Commands retrieve it with Provided. Providing a value of an already
+// provided type replaces the previous value.
+func (c *Commander) Provide(value interface{}) {
+	t := reflect.TypeOf(value)
+	for i, v := range c.provided {
+		if reflect.TypeOf(v) == t {
+			c.provided[i] = value
+			return
+		}
+	}
+	c.provided = append(c.provided, value)
+}
+
+// Provided returns the value of type T provided to the Commander executing
+// the current command. If T is an interface the first provided value
+// implementing T is returned.
+func Provided[T any](ctx context.Context) (T, bool) {
+	values, _ := ctx.Value(providedKey).([]interface{})
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for _, v := range values {
+		if reflect.TypeOf(v) == t {
+			return v.(T), true
+		}
+	}
+	for _, v := range values {
+		if value, ok := v.(T); ok {
+			return value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// MustProvided works like Provided but panics if no value of type T was provided.
+func MustProvided[T any](ctx context.Context) T {
+	value, ok := Provided[T](ctx)
+	if !ok {
+		panic("psubcommands: no value of type " + reflect.TypeOf((*T)(nil)).Elem().String() + " provided")
+	}
+	return value
+}
+
+// Provide makes value available to all subcommands of the DefaultCommander.
+func Provide(value interface{}) { DefaultCommander.Provide(value) }
@@ -9,13 +9,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/pflag"
 )
 
 // ExitStatus represents a Posix exit status that a subcommand
-// expects to be returned to the shell.
+// expects to be returned to the shell. Commands may return any value
+// besides the predefined ones, see also ExitError.
 type ExitStatus int
 
 const (
@@ -25,6 +31,17 @@ const (
 	ExitFailure
 	// ExitUsageError represents a usage error on the command line.
 	ExitUsageError
+	// ExitTimeout represents a subcommand which exceeded its timeout.
+	ExitTimeout
+	// ExitPermissionDenied represents a subcommand the user isn't
+	// authorized to execute.
+	ExitPermissionDenied
+	// ExitLocked represents a subcommand which didn't run because another
+	// instance holds its lock.
+	ExitLocked
+	// ExitCancelled represents a subcommand which was cancelled, e.g. by
+	// a signal.
+	ExitCancelled
 )
 
 // Command represents a single subcommand.
@@ -43,155 +60,851 @@ type Command interface {
 	Execute(ctx context.Context, f *pflag.FlagSet, args ...interface{}) ExitStatus
 }
 
+// LongUsager may be implemented by a Command to provide a detailed,
+// possibly multi-paragraph description shown in its help.
+type LongUsager interface {
+	// Usage returns the long description of the command.
+	Usage() string
+}
+
+// Usager may be implemented by a Command with an invocation syntax the
+// generated help can't express. Its help consists solely of the output of
+// Usage.
+type Usager interface {
+	// Usage writes the complete help of the command to w.
+	Usage(w io.Writer)
+}
+
+// Exampler may be implemented by a Command to show example invocations in its help.
+type Exampler interface {
+	// Examples returns example invocations, usually one per line.
+	Examples() string
+}
+
+// SeeAlsoer may be implemented by a Command to refer to related commands in its help.
+type SeeAlsoer interface {
+	// SeeAlso returns the names of related commands.
+	SeeAlso() []string
+}
+
 type commandGroup struct {
-	name     string
-	commands []Command
+	name        string
+	description string
+	hidden      bool
+	flags       func(f *pflag.FlagSet)
+	commands    []Command
 }
 
 // Commander holds a set of commands.
+// Registering, replacing and unregistering commands is safe for concurrent
+// use, including while commands are dispatched.
 type Commander struct {
+	mu       sync.RWMutex
 	commands []*commandGroup
+	index    map[string]indexEntry
+	help     map[helpKey][]byte
 	topFlags *pflag.FlagSet
+	onError  pflag.ErrorHandling
+	ownFlags bool
 	name     string
+	provided []interface{}
+	topics   []*helpTopic
+
+	annotations map[string]map[string]string
+	atExit      []func()
+	shutdownMu  sync.Mutex
+	serveMu     sync.Mutex
+	onShutdown  []*shutdownHook
+	onStart     []func(ctx context.Context, ev *CommandEvent)
+	onEnd       []func(ctx context.Context, ev *CommandEvent)
+	audits      []AuditSink
+	middlewares []Middleware
+	verbosity   bool
+	aliases     map[string]string
+	aliasFile   string
+	redirects   map[string]string
+	exitCodes   map[ExitStatus]string
+	presets     map[string]map[string]map[string]string
+	statsFile   string
+	deprecated  map[string]map[string]string
+	rcFile      string
+	rcMu        sync.Mutex
+	rc          map[string]map[string]string
+	normalize   func(f *pflag.FlagSet, name string) pflag.NormalizedName
+	fallback    func(ctx context.Context, name string, args []string) ExitStatus
+	helpFormat  string
+	parsedArgs  bool
+
+	// helpRequested is set while printing help requested with -h or --help.
+	helpRequested bool
 
 	// Output specifies where a Commander should write its output.
 	Output io.Writer
+
+	// Error specifies where a Commander should write error messages
+	// and usage printed because of an invalid command line.
+	Error io.Writer
+
+	// Input specifies where a Commander should read user input from.
+	Input io.Reader
+
+	// PromptMissing enables prompting for required flags which weren't
+	// provided on the command line before a command is executed. Prompting
+	// requires interactive input, see Interactive.
+	PromptMissing bool
+
+	// Timeout limits the execution time of every subcommand. Commands
+	// implementing Timeouter override this value. Zero disables the timeout.
+	Timeout time.Duration
+
+	// GroupLess, if set, orders groups in help output. Otherwise groups
+	// are shown in registration order.
+	GroupLess func(a, b string) bool
+
+	// CommandLess, if set, orders commands within a group in help output
+	// and the commands listed by help --sort name. Otherwise commands are
+	// shown in registration order, or ordered by CommandsByNameFold.
+	CommandLess func(a, b Command) bool
+
+	// PinnedGroups are shown before all other groups, in the given order.
+	PinnedGroups []string
+
+	// PrefixMatching allows selecting a subcommand by a unique prefix of its name.
+	PrefixMatching bool
+
+	// Translate, if set, localizes all messages printed by the Commander.
+	// It receives the English fmt format string and must return a format
+	// string with the same verbs in the same order.
+	Translate func(format string) string
+
+	// ExternalCommands enables git-style dispatch of unknown subcommands to
+	// executables named "<name>-<subcommand>" found in PATH.
+	ExternalCommands bool
+
+	// InterspersedGlobalFlags allows top-level flags to appear after the
+	// subcommand name, e.g. "tool status --verbose". Subcommand flags take
+	// precedence over top-level flags of the same name.
+	InterspersedGlobalFlags bool
+
+	// Pager enables showing help output which doesn't fit on the terminal
+	// in $PAGER, falling back to DefaultPager.
+	Pager bool
+
+	// Logger, if set, receives debug logs about dispatch decisions, parse
+	// failures and exit statuses. Commands retrieve it with LoggerFromContext.
+	Logger *slog.Logger
+
+	// ArgumentFiles enables replacing arguments of the form @path on the
+	// command line with the arguments read from path, one per line.
+	ArgumentFiles bool
+
+	// InteractivePicker lets the user select a command interactively if no
+	// subcommand was given and both Input and Output are terminals.
+	InteractivePicker bool
+
+	// HistoryFile, if set, persists the line history of Shell.
+	HistoryFile string
+
+	// ContinueOnScriptError lets ExecuteScript continue with the next line
+	// after a command failed instead of stopping.
+	ContinueOnScriptError bool
+
+	// ShutdownTimeout limits the time the hooks registered with OnShutdown
+	// may take. If zero, DefaultShutdownTimeout is used.
+	ShutdownTimeout time.Duration
+
+	// FlushTimeout limits the time flushing buffered Output and Error may
+	// take before Execute returns. If zero, DefaultFlushTimeout is used.
+	FlushTimeout time.Duration
+
+	// Version, Revision and BuildTime describe the build of the program.
+	// Unset values are read from the build info embedded by the Go toolchain.
+	Version   string
+	Revision  string
+	BuildTime string
+
+	// VersionInHelp prints the version above the help overview.
+	VersionInHelp bool
+
+	// CompactHelp lists only the names of named groups and the number of
+	// their commands in the usage overview. The commands of a group are
+	// listed by "help --group <group>", see RegisterHelpCommand.
+	CompactHelp bool
+
+	// CacheHelp caches rendered help output until commands are registered,
+	// replaced or unregistered. Only enable it if help output doesn't change
+	// otherwise, e.g. by defining aliases after it was first shown.
+	CacheHelp bool
+
+	// HelpPageSize limits the number of commands listed per page by
+	// help --filter, --sort and --page. If zero, DefaultHelpPageSize is
+	// used, a negative value lists all commands on a single page.
+	HelpPageSize int
+
+	// FlagOrder defines the order in which flags are listed in help output.
+	FlagOrder FlagOrder
+
+	// GroupShorthandFlags lists flags having a shorthand before the
+	// remaining flags in help output.
+	GroupShorthandFlags bool
+
+	// License and Notices are printed by the license command. License holds
+	// the license text of the program and Notices the attributions of
+	// third-party code, e.g. a NOTICE file embedded with go:embed.
+	License string
+	Notices string
+
+	// ShellAliases maps shell alias names to command lines of this program,
+	// e.g. "gco": "checkout". The init command defines them in the shell.
+	ShellAliases map[string]string
+
+	// ShellInit holds additional code, e.g. wrapper functions, printed by the
+	// init command for the shell named by the key.
+	ShellInit map[string]string
+
+	// NamespaceSeparator enables hierarchical command names like "db:migrate"
+	// if set to the separator, e.g. ":". Commands sharing a namespace are
+	// listed together in help output and completed by their namespace first.
+	NamespaceSeparator string
+
+	// ExperimentalEnv is the environment variable enabling experimental
+	// and alpha commands. If empty, "<NAME>_ENABLE_EXPERIMENTAL" is used.
+	ExperimentalEnv string
+
+	// NoInputEnv is the environment variable disabling interactive
+	// features, see Interactive. If empty, "<NAME>_NO_INPUT" is used.
+	NoInputEnv string
+
+	// Authorize is called before executing a command implementing Requirer
+	// with the declared requirements. A non-nil error denies the execution
+	// with ExitPermissionDenied. If Authorize is nil, commands with
+	// requirements are always denied.
+	Authorize func(ctx context.Context, cmd Command, requirements []string) error
+
+	// Retry, if set, retries failed commands. Commands implementing
+	// Retrier use their own policy.
+	Retry *RetryPolicy
+
+	// ChainSeparator, if set, lets Execute run multiple subcommands given on
+	// one command line separated by this argument, e.g. "+" for
+	// "tool build + test", see Chain.
+	ChainSeparator string
+
+	// WorkDir is the working directory commands are executed in, unless
+	// they implement WorkDirer. The previous working directory is restored
+	// after a command finished. As the working directory and Env apply to
+	// the whole process, they must not be combined with concurrent
+	// execution, see ExecuteAll.
+	WorkDir string
+
+	// Env holds environment variables set while a command is executed,
+	// overridden by those of commands implementing EnvOverrider. The
+	// previous environment is restored after a command finished.
+	Env map[string]string
+
+	// LookupEnv replaces os.LookupEnv for the environment variables read by
+	// the Commander and by commands using LookupEnv or Getenv.
+	LookupEnv EnvFunc
+
+	// MapContextErrors overrides the ExitStatus of a command with
+	// ExitCancelled or ExitTimeout if the context passed to Execute was
+	// cancelled or exceeded its deadline while the command was running.
+	MapContextErrors bool
+
+	// SafeMode denies commands annotated as destructive unless forced, see
+	// EnableSafeMode.
+	SafeMode bool
+
+	// ForceEnv is the environment variable allowing destructive commands
+	// in safe mode. If empty, "<NAME>_FORCE" is used.
+	ForceEnv string
 }
 
 // NewCommander returns a new commander with specified name.
 // Additionally this function accepts any arguments of the following type:
 // map[string][]Command = Shortcut for multiple command registrations
 // map[string]Command = Shortcut for single command registrations
+// Groups given by maps are registered in alphabetical order.
 // *pflag.FlagSet = Use your own *pflag.FlagSet for this Commander
 // io.Writer = Use your own output instead of os.Stdout
+// pflag.ErrorHandling = Error handling of the top-level flags (default pflag.ExitOnError,
+// or the error handling of a given *pflag.FlagSet)
+// Arguments of other types are ignored, see New for a type safe alternative.
 func NewCommander(name string, args ...interface{}) *Commander {
+	errorHandling, explicit := pflag.ExitOnError, false
 	cdr := &Commander{
 		commands: []*commandGroup{},
 		topFlags: nil,
 		name:     name,
 		Output:   nil,
+		Error:    os.Stderr,
+		Input:    os.Stdin,
 	}
 
 	for _, arg := range args {
 		switch v := arg.(type) {
 		case map[string][]Command:
-			for group, cmds := range v {
-				cdr.Register(group, cmds...)
+			for _, group := range sortedKeys(v) {
+				cdr.Register(group, v[group]...)
 			}
 		case map[string]Command:
-			for group, cmd := range v {
-				cdr.Register(group, cmd)
+			for _, group := range sortedKeys(v) {
+				cdr.Register(group, v[group])
 			}
 		case *pflag.FlagSet:
 			cdr.topFlags = v
 		case io.Writer:
 			cdr.Output = v
+		case pflag.ErrorHandling:
+			errorHandling, explicit = v, true
 		}
 	}
 
-	if cdr.topFlags == nil {
-		cdr.topFlags = pflag.NewFlagSet(name, pflag.ExitOnError)
+	switch {
+	case cdr.topFlags == nil:
+		cdr.topFlags = pflag.NewFlagSet(name, errorHandling)
+		cdr.ownFlags = true
+	case explicit:
+		cdr.topFlags.Init(cdr.topFlags.Name(), errorHandling)
+		cdr.ownFlags = true
+	default:
+		// Keep the error handling of the caller's FlagSet.
+		errorHandling = flagSetErrorHandling(cdr.topFlags)
 	}
+	cdr.onError = errorHandling
 
 	if cdr.Output == nil {
 		cdr.Output = os.Stdout
 	}
 
-	cdr.topFlags.Usage = func() { cdr.explain() }
+	cdr.topFlags.Usage = cdr.usage
 	return cdr
 }
 
 // FlagSet returns the current *pflag.FlagSet used by this Commander.
 func (c *Commander) FlagSet() *pflag.FlagSet { return c.topFlags }
 
+// SetGlobalNormalizationFunc sets a normalization function for the top-level
+// flags and the flags of every subcommand. See pflag.FlagSet.SetNormalizeFunc.
+func (c *Commander) SetGlobalNormalizationFunc(fn func(f *pflag.FlagSet, name string) pflag.NormalizedName) {
+	c.normalize = fn
+	c.topFlags.SetNormalizeFunc(fn)
+}
+
+// commandFlags returns a new FlagSet with all flags of cmd.
+func (c *Commander) commandFlags(cmd Command) *pflag.FlagSet {
+	f := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	if c.normalize != nil {
+		f.SetNormalizeFunc(c.normalize)
+	}
+	cmd.SetFlags(f)
+	c.mergeGroupFlags(cmd, f)
+	c.markDeprecatedFlags(cmd, f)
+	c.addDryRunFlag(cmd, f)
+	c.addOutputFlag(cmd, f)
+	c.addPresetFlag(cmd, f)
+	return f
+}
+
 // Register registers new Commands for the specified group.
 func (c *Commander) Register(group string, cmds ...Command) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g := c.group(group)
+	g.commands = append(g.commands, cmds...)
+	c.index, c.help = nil, nil
+}
+
+// SetGroupDescription sets a description which is shown below the group
+// header in help output. The group is created if it doesn't exist yet.
+func (c *Commander) SetGroupDescription(group, description string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.group(group).description = description
+}
+
+// SetGroupHidden hides or shows a group. Commands of hidden groups can
+// still be executed but are not listed in help output.
+func (c *Commander) SetGroupHidden(group string, hidden bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.group(group).hidden = hidden
+}
+
+// group returns the group with the specified name, creating it if necessary.
+// c.mu must be held for writing.
+func (c *Commander) group(name string) *commandGroup {
 	for _, g := range c.commands {
-		if g.name == group {
-			g.commands = append(g.commands, cmds...)
-			return
+		if g.name == name {
+			return g
 		}
 	}
-	c.commands = append(c.commands, &commandGroup{
-		name:     group,
-		commands: cmds,
-	})
+	g := &commandGroup{
+		name:     name,
+		commands: []Command{},
+	}
+	c.commands = append(c.commands, g)
+	return g
+}
+
+// Unregister removes the command with the specified name.
+// It returns false if no such command is registered.
+func (c *Commander) Unregister(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, g := range c.commands {
+		for i, cmd := range g.commands {
+			if cmd.Name() == name {
+				g.commands = append(g.commands[:i], g.commands[i+1:]...)
+				c.index, c.help = nil, nil
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Replace replaces the command with the specified name by cmd, keeping its group
+// and position. It returns false if no such command is registered.
+func (c *Commander) Replace(name string, cmd Command) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, g := range c.commands {
+		for i, v := range g.commands {
+			if v.Name() == name {
+				g.commands[i] = cmd
+				c.index, c.help = nil, nil
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Execute finds the correct subcommand, executes it and returns it ExitStatus.
-// If the FlagSet wasn't parsed by the user, this will call *pflag.FlagSet.Parse(os.Args[1:]).
+// If the FlagSet wasn't parsed by the user, this will call *pflag.FlagSet.Parse(os.Args[1:]),
+// on every call of Execute, see ExecuteArgs.
 // This will return ExitUsageError if something went wrong while parsing the command line,
 // like subcommand missing.
+// The hooks registered with OnShutdown are run before Execute returns.
 func (c *Commander) Execute(ctx context.Context, args ...interface{}) ExitStatus {
-	if !c.topFlags.Parsed() {
-		c.topFlags.Parse(os.Args[1:])
+	if !c.topFlags.Parsed() || c.parsedArgs {
+		return c.ExecuteArgs(ctx, os.Args[1:], args...)
+	}
+
+	defer c.flushOutput(ctx, c.Output, c.Error)
+	defer c.shutdown(ctx)
+	argv, err := c.expandArgFiles(c.topFlags.Args())
+	if err != nil {
+		fmt.Fprintf(c.Error, c.tr("Failed to read argument file: %s\n"), err)
+		return ExitUsageError
+	}
+	return c.executeArgv(ctx, argv, args...)
+}
+
+// ExecuteArgs is like Execute, but parses argv, the command line without
+// the program name, instead of os.Args. The top-level flags are parsed from
+// argv on every call. If they were parsed before, they are reset to their
+// defaults first, so every call starts from a fresh parse. Calls must not
+// run concurrently, see Clone and ExecuteAll.
+func (c *Commander) ExecuteArgs(ctx context.Context, argv []string, args ...interface{}) ExitStatus {
+	defer c.flushOutput(ctx, c.Output, c.Error)
+	defer c.shutdown(ctx)
+	if c.topFlags.Parsed() {
+		c.topFlags.VisitAll(resetFlag)
 	}
+	c.parsedArgs = true
 
-	if c.topFlags.NArg() < 1 {
+	expanded, err := c.expandArgFiles(argv)
+	if err != nil {
+		fmt.Fprintf(c.Error, c.tr("Failed to read argument file: %s\n"), err)
+		return ExitUsageError
+	}
+
+	// Stop at the subcommand name, remaining flags belong to the subcommand.
+	c.topFlags.SetInterspersed(false)
+	if status, ok := c.parseTopFlags(ctx, expanded); !ok {
+		return status
+	}
+	return c.executeArgv(ctx, c.topFlags.Args(), args...)
+}
+
+// executeArgv executes the command line argv following the top-level flags.
+func (c *Commander) executeArgv(ctx context.Context, argv []string, args ...interface{}) ExitStatus {
+	if len(argv) < 1 && c.InteractivePicker && c.interactive(c.Input, c.getenv) && isTerminal(c.Output) {
+		if cmd, ok := c.pick(); ok {
+			argv = []string{cmd.Name()}
+		}
+	}
+	if len(argv) < 1 {
+		c.debug(ctx, "no subcommand given")
 		c.topFlags.Usage()
 		return ExitUsageError
 	}
 
-	name := c.topFlags.Arg(0)
+	// Commands like __complete receive a partial command line which must
+	// never be executed as a chain.
+	if cmd, ok := c.resolve(argv[0]); ok {
+		if _, raw := unwrap(cmd).(rawArgser); raw {
+			return c.dispatch(ctx, argv, args...)
+		}
+	}
+	if cmdlines := c.splitChain(argv); len(cmdlines) > 1 {
+		return c.Chain(ctx, cmdlines, args...)
+	}
+	return c.dispatch(ctx, argv, args...)
+}
 
-	for _, group := range c.commands {
-		for _, cmd := range group.commands {
-			if name != cmd.Name() {
-				continue
-			}
+// dispatch executes the subcommand named by argv[0] with the remaining
+// elements of argv as its command line.
+func (c *Commander) dispatch(ctx context.Context, argv []string, args ...interface{}) ExitStatus {
+	argv = c.expandRedirect(c.expandAlias(argv))
+	name := argv[0]
+	cmd, ok := c.resolve(name)
+	if !ok {
+		if path, ok := c.externalCommand(name); ok {
+			c.debug(ctx, "dispatching to external command", "name", name, "path", path)
+			return c.executeExternal(ctx, path, argv[1:])
+		}
+		if c.fallback != nil {
+			c.debug(ctx, "dispatching to fallback", "name", name)
+			ctx := c.withContext(ctx)
+			defer c.removeWorkdir(ctx)
+			return c.fallback(ctx, name, argv[1:])
+		}
+		c.debug(ctx, "unknown subcommand", "name", name)
+		c.topFlags.Usage()
+		return ExitUsageError
+	}
+
+	if status := c.checkStability(cmd); status != ExitSuccess {
+		return status
+	}
+
+	c.debug(ctx, "dispatching subcommand", "name", name, "command", cmd.Name())
+	cmdCtx := c.withContext(ctx)
+	defer c.removeWorkdir(cmdCtx)
+	status := c.execute(cmdCtx, cmd, argv[1:], args...)
+	c.debug(ctx, "subcommand finished", "command", cmd.Name(), "status", int(status))
+	return status
+}
+
+// execute parses the command line of cmd, validates it and executes cmd.
+func (c *Commander) execute(ctx context.Context, cmd Command, argv []string, args ...interface{}) ExitStatus {
+	cmd = unwrap(cmd)
+	defer c.flushCommandOutput(ctx, cmd)
+	f := c.commandFlags(cmd)
+	if len(c.audits) == 0 {
+		return c.executeFlags(ctx, cmd, f, argv, args...)
+	}
+
+	// Audited after execution, including denied and invalid invocations.
+	start := time.Now()
+	status := c.executeFlags(ctx, cmd, f, argv, args...)
+	c.audit(ctx, cmd, f, start, status)
+	return status
+}
+
+// executeFlags parses argv into f, validates it and executes cmd.
+func (c *Commander) executeFlags(ctx context.Context, cmd Command, f *pflag.FlagSet, argv []string, args ...interface{}) ExitStatus {
+	f.SetOutput(c.errorOutput(cmd))
+	f.Usage = func() { c.ExplainCommand(f.Output(), cmd) }
+	if c.InterspersedGlobalFlags {
+		c.mergeGlobalFlags(f)
+	}
+	if _, ok := cmd.(rawArgser); ok {
+		argv = append([]string{"--"}, argv...)
+	}
+	if allowsUnknownFlags(cmd) {
+		var unknown []string
+		argv, unknown = splitUnknownFlags(f, argv)
+		ctx = context.WithValue(ctx, unknownFlagsKey, unknown)
+	}
+	if status, ok := c.parseFlags(ctx, cmd, f, argv); !ok {
+		return status
+	}
+	c.warnDeprecated(f)
+	if status := c.applyPreset(cmd, f); status != ExitSuccess {
+		return status
+	}
+	if status := c.applyRC(cmd, f); status != ExitSuccess {
+		return status
+	}
+	if status := c.checkRequired(f); status != ExitSuccess {
+		return status
+	}
+	if status := c.checkEnums(f); status != ExitSuccess {
+		return status
+	}
+	if status := c.checkFlagValidators(f); status != ExitSuccess {
+		return status
+	}
+	if status := c.checkArgs(cmd, f); status != ExitSuccess {
+		return status
+	}
+	ctx = withDryRun(ctx, cmd, f)
+	ctx, status := c.checkOutputFormat(ctx, cmd, f)
+	if status != ExitSuccess {
+		return status
+	}
+	if c.verbosity {
+		// Evaluated after parsing as the flags may follow the subcommand name.
+		ctx = context.WithValue(ctx, verbosityKey, c.verbosityLevel())
+	}
+	if status := c.validate(ctx, cmd, f); status != ExitSuccess {
+		return status
+	}
+	if status := c.checkSafeMode(cmd, f); status != ExitSuccess {
+		return status
+	}
+	if status := c.authorize(ctx, cmd); status != ExitSuccess {
+		return status
+	}
+	unlock, status := c.lock(ctx, cmd)
+	if status != ExitSuccess {
+		return status
+	}
+	defer unlock()
+	status = c.executeWithHooks(ctx, cmd, f, func(ctx context.Context) ExitStatus {
+		return c.executeWithOverrides(ctx, cmd, func(ctx context.Context) ExitStatus {
+			return c.executeWithRetry(ctx, cmd, func(ctx context.Context) ExitStatus {
+				return c.executeWithTimeout(ctx, cmd, func(ctx context.Context) ExitStatus {
+					return c.executeFunc()(ctx, cmd, f, args...)
+				})
+			})
+		})
+	})
+	return c.mapContextError(ctx, cmd, status)
+}
+
+// SetFallback sets fn to be called instead of printing the usage if no
+// command matches the subcommand name. fn receives the name and the
+// remaining command line. External commands take precedence over fn.
+func (c *Commander) SetFallback(fn func(ctx context.Context, name string, args []string) ExitStatus) {
+	c.fallback = fn
+}
+
+// indexEntry is a command in the index and the group it is registered to.
+type indexEntry struct {
+	cmd   Command
+	group *commandGroup
+}
+
+// Lookup returns the command registered with the specified name.
+func (c *Commander) Lookup(name string) (Command, bool) {
+	e, ok := c.lookupEntry(name)
+	return e.cmd, ok
+}
+
+// lookupEntry returns the index entry of the command with the specified name.
+func (c *Commander) lookupEntry(name string) (indexEntry, bool) {
+	c.mu.RLock()
+	index := c.index
+	c.mu.RUnlock()
+	if index == nil {
+		index = c.buildIndex()
+	}
+	e, ok := index[name]
+	return e, ok
+}
+
+// buildIndex builds the index mapping command names to commands, which is
+// used by Lookup until the registered commands change. If several commands
+// share a name the first registered one wins.
+func (c *Commander) buildIndex() map[string]indexEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index != nil {
+		return c.index
+	}
 
-			f := pflag.NewFlagSet(name, pflag.ContinueOnError)
-			f.SetOutput(c.Output)
-			cmd.SetFlags(f)
-			if f.Parse(c.topFlags.Args()[1:]) != nil {
-				return ExitUsageError
+	c.index = map[string]indexEntry{}
+	for _, g := range c.commands {
+		for _, cmd := range g.commands {
+			if _, ok := c.index[cmd.Name()]; !ok {
+				c.index[cmd.Name()] = indexEntry{cmd: cmd, group: g}
 			}
-			return cmd.Execute(ctx, f, args...)
 		}
 	}
+	return c.index
+}
 
-	c.topFlags.Usage()
-	return ExitUsageError
+// VisitCommands calls fn for every registered command in registration order.
+func (c *Commander) VisitCommands(fn func(group string, cmd Command)) {
+	for _, group := range c.groups() {
+		for _, cmd := range group.commands {
+			fn(group.name, cmd)
+		}
+	}
+}
+
+// Explain writes the usage overview of the Commander, as shown by the help
+// command, to w.
+func (c *Commander) Explain(w io.Writer) {
+	c.cachedHelp(w, "", c.explain)
 }
 
-func (c *Commander) explain() {
-	fmt.Fprintf(c.Output, "Usage: %s <flags> <subcommand> <subcommand args>\n\n", c.name)
+func (c *Commander) explain(w io.Writer) {
+	if c.VersionInHelp {
+		fmt.Fprintf(w, "%s\n\n", c.versionLine())
+	}
+	fmt.Fprintf(w, "%s\n\n", c.usageLine(nil))
 
-	flags := c.topFlags.FlagUsages()
+	flags := c.flagUsages(w, c.topFlags)
 	if len(flags) > 0 {
-		fmt.Fprintf(c.Output, "Arguments:\n%s\n", flags)
+		fmt.Fprintf(w, c.tr("Arguments:\n%s\n"), flags)
+	}
+
+	collapsed := []*commandGroup{}
+	for _, v := range c.orderedGroups() {
+		if c.CompactHelp && v.name != "" {
+			collapsed = append(collapsed, v)
+			continue
+		}
+		c.explainGroup(w, v)
+	}
+	c.explainGroupSummaries(w, collapsed)
+
+	c.explainAliases(w)
+	c.explainTopics(w)
+}
+
+// ExplainGroup writes the commands of the named group, as listed in the
+// usage overview, to w. Only the commands of this group are inspected, which
+// keeps rendering fast for programs with many commands. It returns false
+// if no such visible group exists.
+func (c *Commander) ExplainGroup(w io.Writer, name string) bool {
+	for _, g := range c.orderedGroups() {
+		if g.name == name {
+			c.explainGroup(w, g)
+			return true
+		}
+	}
+	return false
+}
+
+// explainGroupSummaries lists groups with the number of their commands.
+func (c *Commander) explainGroupSummaries(w io.Writer, groups []*commandGroup) {
+	if len(groups) == 0 {
+		return
 	}
 
-	for _, v := range c.commands {
-		if len(v.commands) == 0 {
+	buf := bytes.Buffer{}
+	buf.WriteString(c.tr("Command groups:\n"))
+	for _, g := range groups {
+		if len(g.commands) == 0 {
 			continue
 		}
+		summary := c.tr("1 command")
+		if len(g.commands) != 1 {
+			summary = fmt.Sprintf(c.tr("%d commands"), len(g.commands))
+		}
+		if g.description != "" {
+			summary += " - " + g.description
+		}
+		buf.WriteString(overviewLine(w, g.name, summary))
+	}
+	if _, ok := c.Lookup("help"); ok {
+		buf.WriteString(fmt.Sprintf(c.tr("\nRun '%s help --group <group>' to list the commands of a group.\n"), filepath.Base(c.name)))
+	}
+	buf.WriteRune('\n')
+	w.Write(buf.Bytes())
+}
+
+func (c *Commander) explainGroup(w io.Writer, g *commandGroup) {
+	if len(g.commands) == 0 {
+		return
+	}
 
-		buf := bytes.Buffer{}
-		if len(v.name) == 0 {
-			buf.WriteString("Subcommands:\n")
+	plain, namespaces, byNamespace := c.splitNamespaces(g.commands)
+	buf := bytes.Buffer{}
+	if len(plain) > 0 || len(g.description) > 0 {
+		if len(g.name) == 0 {
+			buf.WriteString(c.tr("Subcommands:\n"))
 		} else {
-			buf.WriteString(fmt.Sprintf("%s:\n", v.name))
+			buf.WriteString(fmt.Sprintf("%s:\n", g.name))
 		}
+		if len(g.description) > 0 {
+			buf.WriteString(fmt.Sprintf("%s\n\n", g.description))
+		}
+
+		for _, cmd := range plain {
+			buf.WriteString(overviewLine(w, cmd.Name(), c.synopsis(cmd)))
+		}
+		buf.WriteRune('\n')
+	}
 
-		for _, vv := range v.commands {
-			buf.WriteString(fmt.Sprintf("\t%-15s    %s\n", vv.Name(), vv.Synopsis()))
+	for _, ns := range namespaces {
+		buf.WriteString(fmt.Sprintf(c.tr("%s commands:\n"), ns))
+		for _, cmd := range byNamespace[ns] {
+			buf.WriteString(overviewLine(w, cmd.Name(), c.synopsis(cmd)))
 		}
 		buf.WriteRune('\n')
-		c.Output.Write(buf.Bytes())
 	}
+	w.Write(buf.Bytes())
 }
 
-func (c *Commander) explainCmd(cmd Command) {
-	fmt.Fprintf(c.Output, "Usage: %s <flags> %s <subcommand flags>\n\n%s\n\n", c.name, cmd.Name(), cmd.Synopsis())
+// ExplainCommand writes the usage of cmd, as shown by "help <command>" or
+// -h, to w.
+func (c *Commander) ExplainCommand(w io.Writer, cmd Command) {
+	c.cachedHelp(w, cmd.Name(), func(w io.Writer) { c.explainCommand(w, unwrap(cmd)) })
+}
 
-	f := pflag.NewFlagSet(cmd.Name(), pflag.ExitOnError)
-	cmd.SetFlags(f)
-	flags := f.FlagUsages()
+func (c *Commander) explainCommand(w io.Writer, cmd Command) {
+	if u, ok := cmd.(Usager); ok {
+		u.Usage(w)
+		return
+	}
+	fmt.Fprintf(w, "%s\n\n%s\n\n", c.usageLine(cmd), c.synopsis(cmd))
 
+	if u, ok := cmd.(LongUsager); ok {
+		if usage := strings.TrimSpace(u.Usage()); usage != "" {
+			fmt.Fprintf(w, "%s\n\n", usage)
+		}
+	}
+
+	rest, names, sections := flagSections(c.commandFlags(cmd))
+	flags := c.flagUsages(w, rest)
 	if len(flags) > 0 {
-		fmt.Fprintf(c.Output, "Arguments:\n%s", flags)
+		fmt.Fprintf(w, c.tr("Arguments:\n%s"), flags)
 	}
+	for i, name := range names {
+		if i > 0 || len(flags) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s:\n%s", name, c.flagUsages(w, sections[name]))
+	}
+
+	if e, ok := cmd.(Exampler); ok {
+		if examples := strings.TrimRight(e.Examples(), "\n"); examples != "" {
+			fmt.Fprintf(w, c.tr("\nExamples:\n%s\n"), indent(examples, "  "))
+		}
+	}
+
+	c.explainRC(w, cmd)
+	c.explainPresets(w, cmd)
+
+	if s, ok := cmd.(SeeAlsoer); ok && len(s.SeeAlso()) > 0 {
+		fmt.Fprint(w, c.tr("\nSee also:\n"))
+		for _, name := range s.SeeAlso() {
+			if related, ok := c.Lookup(name); ok {
+				fmt.Fprint(w, overviewLine(w, related.Name(), related.Synopsis()))
+			} else {
+				fmt.Fprintf(w, "\t%s\n", name)
+			}
+		}
+	}
+}
+
+// indent prefixes every non-empty line of s with prefix.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 type helpCommand Commander
@@ -200,30 +913,93 @@ type helpCommand Commander
 func (*helpCommand) Name() string { return "help" }
 
 // Synopsis returns a short description of this command.
-func (*helpCommand) Synopsis() string { return "describe subcommands and their syntax" }
+func (h *helpCommand) Synopsis() string {
+	return (*Commander)(h).tr("describe subcommands and their syntax")
+}
 
 // SetFlags adds the flags to the FlagSet.
-func (*helpCommand) SetFlags(*pflag.FlagSet) {}
+func (h *helpCommand) SetFlags(f *pflag.FlagSet) {
+	f.String("group", "", (*Commander)(h).tr("list the commands of a group"))
+	f.String("filter", "", (*Commander)(h).tr("list the commands matching a glob pattern like 'net*'"))
+	Enum(f, "sort", "group", (*Commander)(h).tr("list the commands by group or by name"), "group", "name")
+	f.Int("page", 1, (*Commander)(h).tr("page of the command list to show"))
+}
+
+// Complete returns the command names and help topics or, for --group, the
+// group names starting with toComplete.
+func (h *helpCommand) Complete(name, toComplete string) []string {
+	if name == "group" {
+		groups := []string{}
+		for _, g := range (*Commander)(h).orderedGroups() {
+			if g.name != "" {
+				groups = append(groups, g.name)
+			}
+		}
+		return groups
+	}
+	if name != "" {
+		return nil
+	}
+	candidates := (*Commander)(h).completeCommandNames(toComplete)
+	for _, t := range h.topics {
+		if strings.HasPrefix(t.name, toComplete) {
+			candidates = append(candidates, t.name)
+		}
+	}
+	return candidates
+}
 
 // Execute executs this command and returns it's ExitStatus.
 func (h *helpCommand) Execute(_ context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	if f.Changed("filter") || f.Changed("sort") || f.Changed("page") {
+		if f.NArg() > 0 {
+			return UsageErrorf(f, (*Commander)(h).tr("--filter, --sort and --page don't accept arguments"))
+		}
+		p := &helpPage{filter: "*"}
+		p.group, _ = f.GetString("group")
+		if filter, _ := f.GetString("filter"); filter != "" {
+			p.filter = filter
+		}
+		p.page, _ = f.GetInt("page")
+		sortBy, _ := f.GetString("sort")
+		p.byName = sortBy == "name"
+		return (*Commander)(h).listCommands(f, p)
+	}
+
+	if group, _ := f.GetString("group"); f.Changed("group") {
+		if f.NArg() > 0 {
+			return UsageErrorf(f, (*Commander)(h).tr("--group doesn't accept arguments"))
+		}
+		if !(*Commander)(h).ExplainGroup(io.Discard, group) {
+			fmt.Fprintf(h.Error, (*Commander)(h).tr("Group %s not found\n"), group)
+			return ExitUsageError
+		}
+		(*Commander)(h).writeHelp(h.Output, func(w io.Writer) { (*Commander)(h).ExplainGroup(w, group) })
+		return ExitSuccess
+	}
+
 	switch f.NArg() {
 	case 0:
-		(*Commander)(h).explain()
+		(*Commander)(h).writeHelp(h.Output, (*Commander)(h).Explain)
 		return ExitSuccess
 
 	case 1:
 		arg := f.Arg(0)
-		for _, group := range h.commands {
-			for _, cmd := range group.commands {
-				if arg != cmd.Name() {
-					continue
-				}
-				(*Commander)(h).explainCmd(cmd)
+		if cmd, ok := (*Commander)(h).Lookup(arg); ok {
+			(*Commander)(h).writeHelp((*Commander)(h).helpOutput(cmd), func(w io.Writer) { (*Commander)(h).ExplainCommand(w, cmd) })
+			return ExitSuccess
+		}
+		if target, ok := (*Commander)(h).redirect(arg); ok {
+			if cmd, ok := (*Commander)(h).Lookup(target); ok {
+				(*Commander)(h).writeHelp((*Commander)(h).helpOutput(cmd), func(w io.Writer) { (*Commander)(h).ExplainCommand(w, cmd) })
 				return ExitSuccess
 			}
 		}
-		fmt.Fprintf(h.Output, "Subcommand %s not understood\n", arg)
+		if topic, ok := (*Commander)(h).lookupTopic(arg); ok {
+			(*Commander)(h).writeHelp(h.Output, func(w io.Writer) { (*Commander)(h).explainTopic(w, topic) })
+			return ExitSuccess
+		}
+		fmt.Fprintf(h.Error, (*Commander)(h).tr("Subcommand %s not understood\n"), arg)
 	}
 
 	f.Usage()
@@ -242,6 +1018,30 @@ func FlagSet() *pflag.FlagSet { return DefaultCommander.topFlags }
 // Register registers the given commands on the DefaultCommander.
 func Register(group string, cmd ...Command) { DefaultCommander.Register(group, cmd...) }
 
+// SetGlobalNormalizationFunc sets the flag normalization function of the DefaultCommander.
+func SetGlobalNormalizationFunc(fn func(f *pflag.FlagSet, name string) pflag.NormalizedName) {
+	DefaultCommander.SetGlobalNormalizationFunc(fn)
+}
+
+// SetGroupDescription sets the description of a group on the DefaultCommander.
+func SetGroupDescription(group, description string) {
+	DefaultCommander.SetGroupDescription(group, description)
+}
+
+// SetGroupHidden hides or shows a group on the DefaultCommander.
+func SetGroupHidden(group string, hidden bool) { DefaultCommander.SetGroupHidden(group, hidden) }
+
+// Unregister removes the command with the specified name from the DefaultCommander.
+func Unregister(name string) bool { return DefaultCommander.Unregister(name) }
+
+// Replace replaces the command with the specified name on the DefaultCommander.
+func Replace(name string, cmd Command) bool { return DefaultCommander.Replace(name, cmd) }
+
+// SetFallback sets the handler for unknown subcommands on the DefaultCommander.
+func SetFallback(fn func(ctx context.Context, name string, args []string) ExitStatus) {
+	DefaultCommander.SetFallback(fn)
+}
+
 // Execute finds the correct subcommand, executes it and returns it ExitStatus.
 // If the FlagSet wasn't parsed by the user, this will call *pflag.FlagSet.Parse(os.Args[1:]).
 // This will return ExitUsageError if something went wrong while parsing the command line,
@@ -253,3 +1053,8 @@ func Execute(ctx context.Context, args ...interface{}) ExitStatus {
 // RegisterHelpCommand registers the default help command to the specified group
 // on the DefaultCommander.
 func RegisterHelpCommand(group string) { DefaultCommander.RegisterHelpCommand(group) }
+
+// ExecuteArgs executes the command line argv on the DefaultCommander.
+func ExecuteArgs(ctx context.Context, argv []string, args ...interface{}) ExitStatus {
+	return DefaultCommander.ExecuteArgs(ctx, argv, args...)
+}
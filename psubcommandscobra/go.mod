@@ -0,0 +1,18 @@
+module github.com/g0dsCookie/psubcommands/psubcommandscobra
+
+go 1.26.0
+
+require (
+	github.com/g0dsCookie/psubcommands v0.0.0
+	github.com/spf13/cobra v1.10.2
+	github.com/spf13/pflag v1.0.10
+)
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/term v0.46.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/g0dsCookie/psubcommands => ../
@@ -0,0 +1,167 @@
+// Package psubcommandscobra mounts github.com/spf13/cobra command trees in a
+// github.com/g0dsCookie/psubcommands Commander, allowing CLIs built with
+// cobra to be consolidated without rewriting their commands. It is a
+// separate module, so programs not using it don't depend on cobra.
+package psubcommandscobra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/g0dsCookie/psubcommands"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type command struct {
+	name string
+	cmd  *cobra.Command
+}
+
+// Wrap returns a psubcommands.Command with the specified name running cmd.
+// The command accepts the flags of cmd including the persistent flags of its
+// parents, validates the arguments with cmd.Args and runs the hooks, the
+// validation of required flags and flag groups and the Run functions in the
+// order cobra would. Failed flag validation results in ExitUsageError.
+func Wrap(name string, cmd *cobra.Command) psubcommands.Command {
+	return &command{name: name, cmd: cmd}
+}
+
+// Mount registers every runnable, non-hidden command of the tree rooted at
+// root to group on cdr. Commands are named by their path below root joined
+// by sep, e.g. "remote:add" for "root remote add" with sep ":". The root
+// itself is registered under its own name if runnable.
+func Mount(cdr *psubcommands.Commander, group string, root *cobra.Command, sep string) {
+	var mount func(path []string, cmd *cobra.Command)
+	mount = func(path []string, cmd *cobra.Command) {
+		if cmd.Hidden {
+			return
+		}
+		if cmd.Runnable() {
+			name := strings.Join(path, sep)
+			if name == "" {
+				name = cmd.Name()
+			}
+			cdr.Register(group, Wrap(name, cmd))
+		}
+		for _, child := range cmd.Commands() {
+			mount(append(path[:len(path):len(path)], child.Name()), child)
+		}
+	}
+	mount(nil, root)
+}
+
+// Name of this command.
+func (c *command) Name() string { return c.name }
+
+// Synopsis returns a short description of this command.
+func (c *command) Synopsis() string { return c.cmd.Short }
+
+// Usage returns the long description of the cobra command.
+func (c *command) Usage() string { return c.cmd.Long }
+
+// Examples returns the examples of the cobra command.
+func (c *command) Examples() string { return c.cmd.Example }
+
+// SetFlags adds the flags of the cobra command to the FlagSet. The flags are
+// shared with the cobra command, so they are reset to their defaults first
+// and values don't leak from one execution into the next.
+func (c *command) SetFlags(f *pflag.FlagSet) {
+	local, inherited := c.cmd.NonInheritedFlags(), c.cmd.InheritedFlags()
+	local.VisitAll(resetFlag)
+	inherited.VisitAll(resetFlag)
+	f.AddFlagSet(local)
+	f.AddFlagSet(inherited)
+}
+
+// resetFlag sets flag back to its default value.
+func resetFlag(flag *pflag.Flag) {
+	flag.Changed = false
+	if s, ok := flag.Value.(pflag.SliceValue); ok {
+		values := []string{}
+		if def := strings.Trim(flag.DefValue, "[]"); def != "" {
+			values = strings.Split(def, ",")
+		}
+		s.Replace(values)
+		return
+	}
+	_ = flag.Value.Set(flag.DefValue)
+}
+
+// Execute runs the cobra command and returns it's ExitStatus.
+func (c *command) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) psubcommands.ExitStatus {
+	args := f.Args()
+	c.cmd.SetContext(ctx)
+	if err := c.cmd.ValidateArgs(args); err != nil {
+		return psubcommands.UsageErrorf(f, "%s", err)
+	}
+	if err := c.run(args); err != nil {
+		if !c.cmd.SilenceErrors {
+			fmt.Fprintf(psubcommands.Streams(ctx).Err, "Error: %s\n", err)
+		}
+		return psubcommands.StatusFromError(err)
+	}
+	return psubcommands.ExitSuccess
+}
+
+// run calls the hooks and Run functions of the cobra command.
+func (c *command) run(args []string) error {
+	cmd := c.cmd
+	steps := []func() error{
+		func() error { return persistentHook(cmd, args, preHook) },
+		func() error { return call(cmd, args, cmd.PreRunE, cmd.PreRun) },
+		func() error { return usageError(cmd.ValidateRequiredFlags()) },
+		func() error { return usageError(cmd.ValidateFlagGroups()) },
+		func() error { return call(cmd, args, cmd.RunE, cmd.Run) },
+		func() error { return call(cmd, args, cmd.PostRunE, cmd.PostRun) },
+		func() error { return persistentHook(cmd, args, postHook) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usageError makes err result in ExitUsageError.
+func usageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &psubcommands.ExitError{Code: int(psubcommands.ExitUsageError), Err: err}
+}
+
+type hookKind int
+
+const (
+	preHook hookKind = iota
+	postHook
+)
+
+// persistentHook calls the persistent hook of kind of the nearest command
+// defining one, starting at cmd, like cobra does by default.
+func persistentHook(cmd *cobra.Command, args []string, kind hookKind) error {
+	for p := cmd; p != nil; p = p.Parent() {
+		runE, run := p.PersistentPreRunE, p.PersistentPreRun
+		if kind == postHook {
+			runE, run = p.PersistentPostRunE, p.PersistentPostRun
+		}
+		if runE != nil || run != nil {
+			return call(cmd, args, runE, run)
+		}
+	}
+	return nil
+}
+
+// call calls runE or, if nil, run.
+func call(cmd *cobra.Command, args []string, runE func(*cobra.Command, []string) error, run func(*cobra.Command, []string)) error {
+	switch {
+	case runE != nil:
+		return runE(cmd, args)
+	case run != nil:
+		run(cmd, args)
+	}
+	return nil
+}
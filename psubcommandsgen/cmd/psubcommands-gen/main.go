@@ -0,0 +1,86 @@
+// Command psubcommands-gen writes a boilerplate psubcommands command and a
+// test for it from a spec file, see package psubcommandsgen for the format.
+// It is meant to be used with go:generate:
+//
+//	//go:generate go run github.com/g0dsCookie/psubcommands/psubcommandsgen/cmd/psubcommands-gen add_user.spec
+//
+// The files "<name>.go" and "<name>_test.go" are written to the current
+// directory unless -dir is given. Existing files are skipped, so the
+// generated skeleton can be edited and go generate run again. Use -force to
+// regenerate them.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/g0dsCookie/psubcommands/psubcommandsgen"
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	pkg := pflag.String("package", os.Getenv("GOPACKAGE"), "package name of the generated files")
+	dir := pflag.String("dir", ".", "directory to write the files to")
+	force := pflag.Bool("force", false, "overwrite existing files instead of skipping them")
+	noTest := pflag.Bool("no-test", false, "don't generate a test")
+	pflag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <spec file|->\n\n", filepath.Base(os.Args[0]))
+		pflag.PrintDefaults()
+	}
+	pflag.Parse()
+	if pflag.NArg() != 1 {
+		pflag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(pflag.Arg(0), *pkg, *dir, *force, *noTest); err != nil {
+		fmt.Fprintf(os.Stderr, "psubcommands-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, pkg, dir string, force, noTest bool) error {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	spec, err := psubcommandsgen.ParseSpec(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	spec.Package = pkg
+	if spec.Package == "" {
+		spec.Package = "main"
+	}
+
+	base := filepath.Join(dir, spec.FileName())
+	if err := write(base+".go", force, func(w io.Writer) error { return psubcommandsgen.Generate(w, spec) }); err != nil {
+		return err
+	}
+	if noTest {
+		return nil
+	}
+	return write(base+"_test.go", force, func(w io.Writer) error { return psubcommandsgen.GenerateTest(w, spec) })
+}
+
+// write writes the output of generate to path. An existing file is kept
+// unless force is set.
+func write(path string, force bool, generate func(w io.Writer) error) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := generate(buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,345 @@
+// Package psubcommandsgen generates boilerplate implementations of
+// github.com/g0dsCookie/psubcommands commands from a short spec, see the
+// psubcommands-gen command for use with go:generate.
+//
+// A spec consists of the command name and synopsis on the first line,
+// followed by one line per flag:
+//
+//	add-user: add a user to the system
+//	--admin bool: grant admin rights
+//	--group string=users: primary group of the user
+//	--timeout duration=30s: time to wait for the directory
+//
+// Supported flag types are bool, string, int, float64, duration and
+// strings. Empty lines and lines starting with "#" are ignored.
+package psubcommandsgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// Spec describes a command to generate.
+type Spec struct {
+	// Package is the name of the package of the generated files.
+	Package string
+
+	// Name is the command name, e.g. "add-user".
+	Name string
+
+	// Synopsis is the short description of the command.
+	Synopsis string
+
+	// Flags are the flags of the command.
+	Flags []*Flag
+}
+
+// Flag describes a flag of a generated command.
+type Flag struct {
+	Name    string
+	Type    string
+	Default string
+	Usage   string
+}
+
+// flagType maps a spec type to its Go type, pflag function and zero value.
+type flagType struct {
+	goType string
+	fn     string
+	zero   string
+}
+
+var flagTypes = map[string]flagType{
+	"bool":     {"bool", "BoolVar", "false"},
+	"string":   {"string", "StringVar", ""},
+	"int":      {"int", "IntVar", "0"},
+	"float64":  {"float64", "Float64Var", "0"},
+	"duration": {"time.Duration", "DurationVar", "0s"},
+	"strings":  {"[]string", "StringSliceVar", ""},
+}
+
+var (
+	commandName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+	flagLine    = regexp.MustCompile(`^--([a-z][a-z0-9-]*)\s+([a-z0-9]+)(?:=(\S*))?\s*(?::\s*(.*))?$`)
+)
+
+// ParseSpec parses a spec in the format described in the package
+// documentation. The Package of the returned Spec is empty.
+func ParseSpec(r io.Reader) (*Spec, error) {
+	spec := &Spec{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if spec.Name == "" {
+			name, synopsis, _ := strings.Cut(text, ":")
+			spec.Name, spec.Synopsis = strings.TrimSpace(name), strings.TrimSpace(synopsis)
+			continue
+		}
+
+		m := flagLine.FindStringSubmatch(text)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected \"--name type[=default]: usage\"", line)
+		}
+		spec.Flags = append(spec.Flags, &Flag{Name: m[1], Type: m[2], Default: m[3], Usage: m[4]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return spec, spec.validate()
+}
+
+func (s *Spec) validate() error {
+	if !commandName.MatchString(s.Name) {
+		return fmt.Errorf("invalid command name %q", s.Name)
+	}
+	seen := map[string]bool{}
+	for _, flag := range s.Flags {
+		if seen[flag.Name] {
+			return fmt.Errorf("duplicate flag --%s", flag.Name)
+		}
+		seen[flag.Name] = true
+		if _, ok := flagTypes[flag.Type]; !ok {
+			return fmt.Errorf("flag --%s: unsupported type %q", flag.Name, flag.Type)
+		}
+		if _, err := flag.defaultValue(); err != nil {
+			return fmt.Errorf("flag --%s: invalid default %q: %w", flag.Name, flag.Default, err)
+		}
+	}
+	return nil
+}
+
+// buildSuffixes are file name suffixes with a meaning to the go tool: test
+// files and the GOOS and GOARCH build constraints.
+var buildSuffixes = map[string]bool{
+	"test": true,
+
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+	"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+	"sparc": true, "sparc64": true, "wasm": true,
+}
+
+// FileName returns the base name of the generated files without extension,
+// e.g. "add_user" for the command "add-user". Names ending in a suffix the
+// go tool treats specially, like "build-linux" or "x-test", get a "_cmd"
+// suffix, so the file isn't excluded from the build.
+func (s *Spec) FileName() string {
+	name := strings.ReplaceAll(s.Name, "-", "_")
+	if i := strings.LastIndexByte(name, '_'); i >= 0 && buildSuffixes[name[i+1:]] {
+		name += "_cmd"
+	}
+	return name
+}
+
+// defaultValue returns the Go expression of the default value of f.
+func (f *Flag) defaultValue() (string, error) {
+	def := f.Default
+	if def == "" {
+		def = flagTypes[f.Type].zero
+	}
+	switch f.Type {
+	case "bool":
+		b, err := strconv.ParseBool(def)
+		return strconv.FormatBool(b), err
+	case "int":
+		i, err := strconv.Atoi(def)
+		return strconv.Itoa(i), err
+	case "float64":
+		v, err := strconv.ParseFloat(def, 64)
+		if err == nil && (math.IsInf(v, 0) || math.IsNaN(v)) {
+			err = fmt.Errorf("not a finite number")
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), err
+	case "duration":
+		d, err := time.ParseDuration(def)
+		return durationValue(d), err
+	case "strings":
+		if def == "" {
+			return "nil", nil
+		}
+		return fmt.Sprintf("%#v", strings.Split(def, ",")), nil
+	}
+	return strconv.Quote(def), nil
+}
+
+// durationValue returns d as a Go expression like "30 * time.Second".
+func durationValue(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{{time.Hour, "Hour"}, {time.Minute, "Minute"}, {time.Second, "Second"}, {time.Millisecond, "Millisecond"}, {time.Microsecond, "Microsecond"}}
+	if d == 0 {
+		return "0"
+	}
+	for _, unit := range units {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * time.%s", d/unit.d, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d", d)
+}
+
+// camel converts a dashed name to camel case, optionally exported.
+func camel(name string, exported bool) string {
+	var b strings.Builder
+	upper := exported
+	for _, r := range name {
+		switch {
+		case r == '-':
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fieldName returns the struct field holding the value of the flag name.
+// Names which are Go keywords, like "type", get a "Flag" suffix.
+func fieldName(name string) string {
+	field := camel(name, false)
+	if token.IsKeyword(field) {
+		field += "Flag"
+	}
+	return field
+}
+
+type templateFlag struct {
+	*Flag
+	Field   string
+	Fn      string
+	GoType  string
+	Default string
+}
+
+type templateData struct {
+	*Spec
+	Type        string
+	Test        string
+	Flags       []templateFlag
+	HasDuration bool
+}
+
+func (s *Spec) data() (*templateData, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if s.Package == "" {
+		return nil, fmt.Errorf("package name required")
+	}
+	d := &templateData{Spec: s, Type: camel(s.Name, false) + "Command", Test: "Test" + camel(s.Name, true) + "Command"}
+	for _, flag := range s.Flags {
+		t := flagTypes[flag.Type]
+		def, _ := flag.defaultValue()
+		d.Flags = append(d.Flags, templateFlag{Flag: flag, Field: fieldName(flag.Name), Fn: t.fn, GoType: t.goType, Default: def})
+		d.HasDuration = d.HasDuration || flag.Type == "duration"
+	}
+	return d, nil
+}
+
+var commandTemplate = template.Must(template.New("command").Parse(`package {{.Package}}
+
+import (
+	"context"
+{{- if .HasDuration}}
+	"time"
+{{- end}}
+
+	"github.com/g0dsCookie/psubcommands"
+	"github.com/spf13/pflag"
+)
+
+// {{.Type}} implements the "{{.Name}}" command.
+type {{.Type}} struct {
+{{- range .Flags}}
+	{{.Field}} {{.GoType}}
+{{- end}}
+}
+
+// Name of this command.
+func (*{{.Type}}) Name() string { return {{printf "%q" .Name}} }
+
+// Synopsis returns a short description of this command.
+func (*{{.Type}}) Synopsis() string { return {{printf "%q" .Synopsis}} }
+
+// SetFlags adds the flags to the FlagSet.
+func (c *{{.Type}}) SetFlags(f *pflag.FlagSet) {
+{{- range .Flags}}
+	f.{{.Fn}}(&c.{{.Field}}, {{printf "%q" .Name}}, {{.Default}}, {{printf "%q" .Usage}})
+{{- end}}
+}
+
+// Execute executes this command and returns its ExitStatus.
+func (c *{{.Type}}) Execute(ctx context.Context, f *pflag.FlagSet, args ...interface{}) psubcommands.ExitStatus {
+	// TODO: implement {{.Name}}.
+	return psubcommands.ExitSuccess
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Parse(`package {{.Package}}
+
+import (
+	"testing"
+
+	"github.com/g0dsCookie/psubcommands"
+	"github.com/g0dsCookie/psubcommands/psubcommandstest"
+	"github.com/spf13/pflag"
+)
+
+func {{.Test}}(t *testing.T) {
+	cdr := psubcommands.NewCommander("test", pflag.ContinueOnError)
+	cdr.Register("", &{{.Type}}{})
+
+	result := psubcommandstest.Run(t, cdr, {{printf "%q" .Name}})
+	if result.Status != psubcommands.ExitSuccess {
+		t.Fatalf("exit status %d, stderr: %s", result.Status, result.Stderr)
+	}
+}
+`))
+
+// Generate writes the implementation of the command described by spec to w.
+func Generate(w io.Writer, spec *Spec) error { return execute(w, commandTemplate, spec) }
+
+// GenerateTest writes a test executing the generated command to w.
+func GenerateTest(w io.Writer, spec *Spec) error { return execute(w, testTemplate, spec) }
+
+func execute(w io.Writer, t *template.Template, spec *Spec) error {
+	data, err := spec.data()
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
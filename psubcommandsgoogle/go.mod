@@ -0,0 +1,17 @@
+module github.com/g0dsCookie/psubcommands/psubcommandsgoogle
+
+go 1.26.0
+
+require (
+	github.com/g0dsCookie/psubcommands v0.0.0
+	github.com/google/subcommands v1.2.0
+	github.com/spf13/pflag v1.0.10
+)
+
+require (
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/term v0.46.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/g0dsCookie/psubcommands => ../
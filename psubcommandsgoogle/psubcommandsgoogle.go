@@ -0,0 +1,74 @@
+// Package psubcommandsgoogle adapts commands written for
+// github.com/google/subcommands to github.com/g0dsCookie/psubcommands,
+// allowing existing commands to be migrated one at a time. It is a separate
+// module, so programs not using it don't depend on google/subcommands.
+package psubcommandsgoogle
+
+import (
+	"context"
+	"flag"
+
+	"github.com/g0dsCookie/psubcommands"
+	"github.com/google/subcommands"
+	"github.com/spf13/pflag"
+)
+
+type command struct {
+	cmd   subcommands.Command
+	flags *flag.FlagSet
+}
+
+// Wrap returns a psubcommands.Command executing cmd. The flags defined by cmd
+// are translated to pflag, so single letter flags become shorthands and
+// long flags require two dashes. The Usage of cmd is used as long usage.
+//
+// The *flag.FlagSet passed to cmd holds the positional arguments only,
+// Visit doesn't report the flags set on the command line.
+func Wrap(cmd subcommands.Command) psubcommands.Command {
+	return &command{cmd: cmd}
+}
+
+// Register wraps cmds and registers them for group on cdr.
+func Register(cdr *psubcommands.Commander, group string, cmds ...subcommands.Command) {
+	for _, cmd := range cmds {
+		cdr.Register(group, Wrap(cmd))
+	}
+}
+
+// Name of this command.
+func (c *command) Name() string { return c.cmd.Name() }
+
+// Synopsis returns a short description of this command.
+func (c *command) Synopsis() string { return c.cmd.Synopsis() }
+
+// Usage returns the usage of the wrapped command.
+func (c *command) Usage() string { return c.cmd.Usage() }
+
+// SetFlags adds the flags of the wrapped command to the FlagSet.
+func (c *command) SetFlags(f *pflag.FlagSet) {
+	c.flags = flag.NewFlagSet(c.cmd.Name(), flag.ContinueOnError)
+	c.cmd.SetFlags(c.flags)
+	f.AddGoFlagSet(c.flags)
+}
+
+// Execute executs the wrapped command and returns it's ExitStatus.
+func (c *command) Execute(ctx context.Context, f *pflag.FlagSet, args ...interface{}) psubcommands.ExitStatus {
+	// The values were already set through f, only hand over the arguments.
+	if err := c.flags.Parse(append([]string{"--"}, f.Args()...)); err != nil {
+		return psubcommands.ExitUsageError
+	}
+	return toExitStatus(c.cmd.Execute(ctx, c.flags, args...))
+}
+
+// toExitStatus translates the ExitStatus of google/subcommands.
+func toExitStatus(status subcommands.ExitStatus) psubcommands.ExitStatus {
+	switch status {
+	case subcommands.ExitSuccess:
+		return psubcommands.ExitSuccess
+	case subcommands.ExitUsageError:
+		return psubcommands.ExitUsageError
+	case subcommands.ExitFailure:
+		return psubcommands.ExitFailure
+	}
+	return psubcommands.ExitStatus(status)
+}
@@ -0,0 +1,53 @@
+// Package psubcommandsotel implements OpenTelemetry tracing for commands
+// executed by a github.com/g0dsCookie/psubcommands Commander. It is a
+// separate module, so programs not using it don't depend on OpenTelemetry.
+package psubcommandsotel
+
+import (
+	"context"
+
+	"github.com/g0dsCookie/psubcommands"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for the default tracer.
+const ScopeName = "github.com/g0dsCookie/psubcommands/psubcommandsotel"
+
+const (
+	// CommandKey is the span attribute holding the command name.
+	CommandKey = attribute.Key("psubcommands.command")
+	// ExitStatusKey is the span attribute holding the exit status.
+	ExitStatusKey = attribute.Key("psubcommands.exit_status")
+)
+
+// Middleware returns a psubcommands.Middleware wrapping every command
+// execution in a span named after the command. The span context is
+// propagated to the command. If tracer is nil the global TracerProvider is used.
+func Middleware(tracer trace.Tracer) psubcommands.Middleware {
+	if tracer == nil {
+		tracer = otel.Tracer(ScopeName)
+	}
+
+	return func(next psubcommands.ExecuteFunc) psubcommands.ExecuteFunc {
+		return func(ctx context.Context, cmd psubcommands.Command, f *pflag.FlagSet, args ...interface{}) psubcommands.ExitStatus {
+			ctx, span := tracer.Start(ctx, cmd.Name(), trace.WithAttributes(CommandKey.String(cmd.Name())))
+			defer span.End()
+
+			status := next(ctx, cmd, f, args...)
+			span.SetAttributes(ExitStatusKey.Int(int(status)))
+			if status != psubcommands.ExitSuccess {
+				span.SetStatus(codes.Error, "command exited with non-zero status")
+			}
+			return status
+		}
+	}
+}
+
+// Instrument adds tracing to all commands executed by cdr.
+func Instrument(cdr *psubcommands.Commander, tracer trace.Tracer) {
+	cdr.Use(Middleware(tracer))
+}
@@ -0,0 +1,54 @@
+package psubcommandstest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/g0dsCookie/psubcommands"
+)
+
+// UpdateEnv names the environment variable which, when set to a non-empty
+// value, makes AssertGolden rewrite golden files instead of comparing them.
+const UpdateEnv = "PSUBCOMMANDSTEST_UPDATE"
+
+// Help renders the help output of cdr by running its help command with args,
+// e.g. Help(t, cdr) for the overview or Help(t, cdr, "push") for a single
+// command. Groups and commands are rendered in registration order, so the
+// output is stable between runs as long as registration is.
+// cdr must have a help command registered (see Commander.RegisterHelpCommand).
+func Help(t testing.TB, cdr *psubcommands.Commander, args ...string) string {
+	t.Helper()
+
+	result := Run(t, cdr, append([]string{"help"}, args...)...)
+	if result.Status != psubcommands.ExitSuccess {
+		t.Fatalf("psubcommandstest: help %v returned %d: %s", args, result.Status, result.Stderr)
+	}
+	return result.Stdout
+}
+
+// AssertGolden compares got against the golden file testdata/<name>.golden
+// and fails the test if they differ. If the environment variable UpdateEnv
+// is set the golden file is written instead.
+func AssertGolden(t testing.TB, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("psubcommandstest: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("psubcommandstest: %s", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("psubcommandstest: %s (set %s=1 to create it)", err, UpdateEnv)
+	}
+	if string(want) != got {
+		t.Errorf("psubcommandstest: output differs from %s\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
@@ -0,0 +1,51 @@
+// Package psubcommandstest implements utilities for testing command line
+// interfaces built with github.com/g0dsCookie/psubcommands.
+package psubcommandstest
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/g0dsCookie/psubcommands"
+)
+
+// Result holds the outcome of a single execution of a Commander.
+type Result struct {
+	// Status is the ExitStatus returned by the Commander.
+	Status psubcommands.ExitStatus
+
+	// Stdout holds everything written to the Commanders Output.
+	Stdout string
+
+	// Stderr holds everything written to the Commanders Error.
+	Stderr string
+}
+
+// Run executes cdr with the command line args and captures its output.
+// cdr should be created with pflag.ContinueOnError, otherwise invalid top
+// level flags exit the test binary.
+func Run(t testing.TB, cdr *psubcommands.Commander, args ...string) *Result {
+	t.Helper()
+	return RunInput(t, cdr, "", args...)
+}
+
+// RunInput works like Run but provides input as the Commanders Input.
+func RunInput(t testing.TB, cdr *psubcommands.Commander, input string, args ...string) *Result {
+	t.Helper()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	output, errOutput, in := cdr.Output, cdr.Error, cdr.Input
+	cdr.Output, cdr.Error, cdr.Input = stdout, stderr, strings.NewReader(input)
+	defer func() { cdr.Output, cdr.Error, cdr.Input = output, errOutput, in }()
+
+	flagOutput := cdr.FlagSet().Output()
+	cdr.FlagSet().SetOutput(stderr)
+	defer cdr.FlagSet().SetOutput(flagOutput)
+	result := &Result{Status: cdr.ExecuteArgs(context.Background(), args)}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	return result
+}
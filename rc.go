@@ -0,0 +1,159 @@
+package psubcommands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// NoRCFlag is the name of the top-level flag disabling the rc file.
+const NoRCFlag = "no-rc"
+
+// EnableRCFile enables reading default flag values for subcommands from the
+// file at path. The file contains one section per subcommand:
+//
+//	# comment
+//	[push]
+//	force = true
+//	remote = origin
+//
+// Values are applied to flags not given on the command line. Sections like
+// [preset push mirror] define presets, see RegisterPreset. The top-level
+// flag --no-rc disables reading the file. A missing file is ignored.
+func (c *Commander) EnableRCFile(path string) {
+	c.rcFile = path
+	if c.topFlags.Lookup(NoRCFlag) == nil {
+		c.topFlags.Bool(NoRCFlag, false, fmt.Sprintf(c.tr("don't read defaults from %s"), path))
+	}
+}
+
+// EnableRCFile enables the rc file on the DefaultCommander.
+func EnableRCFile(path string) { DefaultCommander.EnableRCFile(path) }
+
+// RCDefaults returns the default flag values for the command name read from
+// the rc file. It returns nil if the rc file is disabled or doesn't exist.
+func (c *Commander) RCDefaults(name string) (map[string]string, error) {
+	rc, err := c.loadRC()
+	return rc[name], err
+}
+
+// loadRC returns all sections of the rc file. It returns nil if the rc file
+// is disabled or doesn't exist.
+func (c *Commander) loadRC() (map[string]map[string]string, error) {
+	if c.rcFile == "" {
+		return nil, nil
+	}
+	if noRC, _ := c.topFlags.GetBool(NoRCFlag); noRC {
+		return nil, nil
+	}
+
+	// Concurrent executions share the rc file, which is read once.
+	c.rcMu.Lock()
+	defer c.rcMu.Unlock()
+	if c.rc == nil {
+		file, err := os.Open(c.rcFile)
+		if os.IsNotExist(err) {
+			c.rc = map[string]map[string]string{}
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		rc, err := parseRC(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.rcFile, err)
+		}
+		c.rc = rc
+	}
+	return c.rc, nil
+}
+
+// applyRC sets all flags of f not given on the command line to the values
+// of the rc file.
+func (c *Commander) applyRC(cmd Command, f *pflag.FlagSet) ExitStatus {
+	if _, err := c.setRCDefaults(cmd, f); err != nil {
+		fmt.Fprintln(c.Error, err)
+		return ExitUsageError
+	}
+	return ExitSuccess
+}
+
+// setRCDefaults sets all flags of f not given on the command line to the
+// values of the rc file and returns the names of the flags it set.
+func (c *Commander) setRCDefaults(cmd Command, f *pflag.FlagSet) ([]string, error) {
+	defaults, err := c.RCDefaults(cmd.Name())
+	if err != nil {
+		return nil, fmt.Errorf(c.tr("Failed to read rc file: %s"), err)
+	}
+
+	set := []string{}
+	for _, name := range sortedKeys(defaults) {
+		flag := f.Lookup(name)
+		if flag == nil {
+			return nil, fmt.Errorf(c.tr("Unknown flag --%s for %s in %s"), name, cmd.Name(), c.rcFile)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := f.Set(name, defaults[name]); err != nil {
+			return nil, fmt.Errorf(c.tr("Invalid value for --%s in %s: %s"), name, c.rcFile, maskValue(flag, defaults[name], err))
+		}
+		set = append(set, name)
+	}
+	return set, nil
+}
+
+// explainRC writes the defaults of cmd read from the rc file to w.
+func (c *Commander) explainRC(w io.Writer, cmd Command) {
+	defaults, err := c.RCDefaults(cmd.Name())
+	if err != nil || len(defaults) == 0 {
+		return
+	}
+	f := c.commandFlags(cmd)
+	fmt.Fprintf(w, c.tr("\nDefaults from %s:\n"), c.rcFile)
+	for _, name := range sortedKeys(defaults) {
+		value := defaults[name]
+		if flag := f.Lookup(name); flag != nil && isSecret(flag) {
+			value = MaskedValue
+		}
+		fmt.Fprintf(w, "  --%s=%s\n", name, value)
+	}
+}
+
+// parseRC parses rc file contents into a map of sections to key value pairs.
+func parseRC(r io.Reader) (map[string]map[string]string, error) {
+	rc := map[string]map[string]string{}
+	var section map[string]string
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";"):
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			if rc[name] == nil {
+				rc[name] = map[string]string{}
+			}
+			section = rc[name]
+			continue
+		case section == nil:
+			return nil, fmt.Errorf("line %d: value outside of a [command] section", line)
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		value := "true"
+		if len(parts) == 2 {
+			value = strings.TrimSpace(parts[1])
+		}
+		section[key] = value
+	}
+	return rc, scanner.Err()
+}
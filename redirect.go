@@ -0,0 +1,50 @@
+package psubcommands
+
+import "fmt"
+
+// RegisterRedirect dispatches the command name oldName to the command
+// newName, e.g. after renaming a command, printing a notice about the new
+// name to Error. Registered commands and aliases take precedence over
+// redirects.
+func (c *Commander) RegisterRedirect(oldName, newName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.redirects == nil {
+		c.redirects = map[string]string{}
+	}
+	c.redirects[oldName] = newName
+}
+
+// RegisterRedirect registers a redirect on the DefaultCommander.
+func RegisterRedirect(oldName, newName string) { DefaultCommander.RegisterRedirect(oldName, newName) }
+
+// redirect returns the name name is redirected to, following chained
+// redirects.
+func (c *Commander) redirect(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	target, ok := c.redirects[name]
+	for i := 0; ok && i < len(c.redirects); i++ {
+		next, chained := c.redirects[target]
+		if !chained {
+			break
+		}
+		target = next
+	}
+	return target, ok
+}
+
+// expandRedirect replaces the first element of argv with the command it is
+// redirected to and prints a notice.
+func (c *Commander) expandRedirect(argv []string) []string {
+	if _, ok := c.Lookup(argv[0]); ok {
+		return argv
+	}
+	target, ok := c.redirect(argv[0])
+	if !ok {
+		return argv
+	}
+
+	fmt.Fprintf(c.Error, c.tr("Subcommand %s was renamed to %s, please use %s instead\n"), argv[0], target, target)
+	return append([]string{target}, argv[1:]...)
+}
@@ -0,0 +1,54 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Resulter may be implemented by a Command returning a structured result
+// instead of formatting its output itself. The Commander calls Result
+// instead of Execute and renders a non-nil result to Out in the format
+// selected with -o/--output, which is added to such commands like for
+// OutputFormatter. ExecuteResult hands the result to the caller instead.
+type Resulter interface {
+	Result(ctx context.Context, f *pflag.FlagSet, args ...interface{}) (interface{}, ExitStatus)
+}
+
+// resultHolder receives the result of a Resulter executed by ExecuteResult.
+type resultHolder struct {
+	value interface{}
+}
+
+// ExecuteResult works like ExecuteArgs but returns the result of a command
+// implementing Resulter instead of rendering it. If the command line runs
+// several commands, e.g. with ChainSeparator, the last result is returned.
+func (c *Commander) ExecuteResult(ctx context.Context, argv []string, args ...interface{}) (interface{}, ExitStatus) {
+	holder := &resultHolder{}
+	status := c.ExecuteArgs(context.WithValue(ctx, resultKey, holder), argv, args...)
+	return holder.value, status
+}
+
+// ExecuteResult executes the command line argv on the DefaultCommander and
+// returns the result, see Commander.ExecuteResult.
+func ExecuteResult(ctx context.Context, argv []string, args ...interface{}) (interface{}, ExitStatus) {
+	return DefaultCommander.ExecuteResult(ctx, argv, args...)
+}
+
+// executeResult calls r and renders or stores its result.
+func (c *Commander) executeResult(ctx context.Context, r Resulter, f *pflag.FlagSet, args ...interface{}) ExitStatus {
+	result, status := r.Result(ctx, f, args...)
+	if holder, ok := ctx.Value(resultKey).(*resultHolder); ok {
+		holder.value = result
+		return status
+	}
+	if result == nil {
+		return status
+	}
+	if err := Render(ctx, OutputFormat(ctx), result); err != nil {
+		fmt.Fprintf(Streams(ctx).Err, c.tr("Failed to render result: %s\n"), err)
+		return Worst(status, ExitFailure)
+	}
+	return status
+}
@@ -0,0 +1,102 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy configures how often and when a failed command is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of executions including the first
+	// one. Values below 2 disable retries.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry.
+	Backoff time.Duration
+
+	// Multiplier increases the delay for every further retry. Values
+	// below 1 keep the delay constant.
+	Multiplier float64
+
+	// MaxBackoff limits the delay if non-zero.
+	MaxBackoff time.Duration
+
+	// RetryOn lists the ExitStatus values which are retried. If empty and
+	// RetryIf is nil, every status except ExitSuccess, ExitUsageError and
+	// ExitPermissionDenied is retried.
+	RetryOn []ExitStatus
+
+	// RetryIf, if set, decides whether status is retried instead of RetryOn.
+	RetryIf func(status ExitStatus) bool
+}
+
+// Retrier may be implemented by a Command to declare its own RetryPolicy,
+// e.g. for commands depending on flaky networks.
+type Retrier interface {
+	RetryPolicy() RetryPolicy
+}
+
+// retries reports whether status should be retried.
+func (p *RetryPolicy) retries(status ExitStatus) bool {
+	switch {
+	case status == ExitSuccess:
+		return false
+	case p.RetryIf != nil:
+		return p.RetryIf(status)
+	case len(p.RetryOn) > 0:
+		for _, s := range p.RetryOn {
+			if s == status {
+				return true
+			}
+		}
+		return false
+	}
+	return status != ExitUsageError && status != ExitPermissionDenied
+}
+
+// delay returns the delay before the retry following attempt.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(p.Backoff)
+	for i := 1; i < attempt && p.Multiplier > 1; i++ {
+		d *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// retryPolicy returns the RetryPolicy for cmd. A Retrier takes precedence
+// over the Commander wide Retry.
+func (c *Commander) retryPolicy(cmd Command) *RetryPolicy {
+	if r, ok := cmd.(Retrier); ok {
+		p := r.RetryPolicy()
+		return &p
+	}
+	return c.Retry
+}
+
+// executeWithRetry executes cmd again as long as its RetryPolicy allows.
+func (c *Commander) executeWithRetry(ctx context.Context, cmd Command, exec func(context.Context) ExitStatus) ExitStatus {
+	policy := c.retryPolicy(cmd)
+	if policy == nil || policy.MaxAttempts < 2 {
+		return exec(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		status := exec(ctx)
+		if attempt >= policy.MaxAttempts || !policy.retries(status) {
+			return status
+		}
+
+		delay := policy.delay(attempt)
+		fmt.Fprintf(c.Error, c.tr("Subcommand %s failed with status %d, retrying in %s (attempt %d/%d)\n"),
+			cmd.Name(), int(status), delay, attempt+1, policy.MaxAttempts)
+		select {
+		case <-ctx.Done():
+			return status
+		case <-time.After(delay):
+		}
+	}
+}
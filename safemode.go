@@ -0,0 +1,65 @@
+package psubcommands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// AnnotationDestructive marks a command changing or deleting data when set
+// to "true", see Annotate and SafeMode.
+const AnnotationDestructive = "destructive"
+
+// ForceFlag is the name of the top-level flag registered by EnableSafeMode.
+const ForceFlag = "force"
+
+// EnableSafeMode enables SafeMode and registers the top-level flag --force
+// allowing destructive commands, e.g. for read-only operator accounts
+// sharing the binary. Blocked commands are printed and denied with
+// ExitPermissionDenied.
+func (c *Commander) EnableSafeMode() {
+	c.SafeMode = true
+	if c.topFlags.Lookup(ForceFlag) == nil {
+		c.topFlags.Bool(ForceFlag, false, c.tr("allow destructive commands in safe mode"))
+	}
+}
+
+// EnableSafeMode enables safe mode on the DefaultCommander.
+func EnableSafeMode() { DefaultCommander.EnableSafeMode() }
+
+// forceEnv returns the environment variable allowing destructive commands.
+func (c *Commander) forceEnv() string {
+	if c.ForceEnv != "" {
+		return c.ForceEnv
+	}
+	name := strings.ToUpper(filepath.Base(c.name))
+	return nonEnvChars.ReplaceAllString(name, "_") + "_FORCE"
+}
+
+// destructive reports whether cmd is annotated as destructive.
+func (c *Commander) destructive(cmd Command) bool {
+	v, _ := c.Annotation(cmd, AnnotationDestructive)
+	return envTrue(v)
+}
+
+// checkSafeMode denies destructive commands in safe mode unless forced.
+func (c *Commander) checkSafeMode(cmd Command, f *pflag.FlagSet) ExitStatus {
+	if !c.SafeMode || !c.destructive(cmd) {
+		return ExitSuccess
+	}
+	if force, _ := c.topFlags.GetBool(ForceFlag); force || envTrue(c.getenv(c.forceEnv())) {
+		return ExitSuccess
+	}
+
+	line := []string{filepath.Base(c.name), cmd.Name()}
+	flags := changedFlags(f)
+	for _, name := range sortedKeys(flags) {
+		line = append(line, fmt.Sprintf("--%s=%s", name, flags[name]))
+	}
+	line = append(line, f.Args()...)
+	fmt.Fprintf(c.Error, c.tr("Blocked in safe mode: %s\n"), strings.Join(line, " "))
+	fmt.Fprintf(c.Error, c.tr("Subcommand %s is destructive, use --%s or set %s=1 to run it\n"), cmd.Name(), ForceFlag, c.forceEnv())
+	return ExitPermissionDenied
+}
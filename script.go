@@ -0,0 +1,54 @@
+package psubcommands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExecuteScript reads subcommand invocations from r, one per line, and
+// executes them in order. Blank lines and lines starting with "#" are
+// skipped. Execution stops at the first command not returning ExitSuccess
+// unless ContinueOnScriptError is set. ExecuteScript returns the ExitStatus
+// of the failed command, or the Worst status of all commands if execution
+// continued after failures.
+func (c *Commander) ExecuteScript(ctx context.Context, r io.Reader, args ...interface{}) ExitStatus {
+	status, worst := ExitSuccess, ExitSuccess
+	scanner := bufio.NewScanner(r)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		if ctx.Err() != nil {
+			return ExitFailure
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		argv, err := splitWords(line)
+		if err != nil {
+			fmt.Fprintf(c.Error, c.tr("Line %d: %s\n"), lineno, err)
+			status = ExitUsageError
+		} else {
+			// Arguments may hold secrets, so only the subcommand name is logged.
+			c.debug(ctx, "executing script line", "line", lineno, "name", argv[0])
+			status = c.dispatch(ctx, argv, args...)
+		}
+		if status != ExitSuccess && !c.ContinueOnScriptError {
+			return status
+		}
+		worst = Worst(worst, status)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(c.Error, c.tr("Failed to read script: %s\n"), err)
+		return ExitFailure
+	}
+	return worst
+}
+
+// ExecuteScript executes the script read from r on the DefaultCommander.
+func ExecuteScript(ctx context.Context, r io.Reader, args ...interface{}) ExitStatus {
+	return DefaultCommander.ExecuteScript(ctx, r, args...)
+}
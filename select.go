@@ -0,0 +1,46 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SelectByBasename returns the Commander named like the invoked executable,
+// busybox-style, so one binary installed or linked under several names,
+// e.g. "tool-admin" and "tool-user", offers a different set of commands
+// under each name. A ".exe" extension is ignored.
+func SelectByBasename(commanders map[string]*Commander) (*Commander, bool) {
+	c, ok := commanders[basename(os.Args[0])]
+	return c, ok
+}
+
+// ExecuteSelected executes the Commander selected by SelectByBasename with
+// the command line. If the executable name doesn't select one, the first
+// argument does, e.g. "tool admin <subcommand>", and the Commander is
+// executed with the remaining arguments. Otherwise the available names are
+// printed and ExitUsageError is returned.
+func ExecuteSelected(ctx context.Context, commanders map[string]*Commander, args ...interface{}) ExitStatus {
+	if c, ok := SelectByBasename(commanders); ok {
+		return c.ExecuteArgs(ctx, os.Args[1:], args...)
+	}
+	if len(os.Args) > 1 {
+		if c, ok := commanders[os.Args[1]]; ok {
+			return c.ExecuteArgs(ctx, os.Args[2:], args...)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Usage: %s <%s> <subcommand> <subcommand args>\n", basename(os.Args[0]), strings.Join(sortedKeys(commanders), "|"))
+	return ExitUsageError
+}
+
+// basename returns the name of the executable path without ".exe".
+func basename(path string) string {
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
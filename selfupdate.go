@@ -0,0 +1,308 @@
+package psubcommands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Release describes a release of the program offered by an Updater.
+type Release struct {
+	// Version of the release, e.g. "v1.2.3".
+	Version string
+
+	// URL of the program binary for the running platform. Archives are
+	// not unpacked.
+	URL string
+
+	// Checksum is the hex encoded SHA-256 checksum of the binary. Releases
+	// without a checksum are rejected unless InsecureSkipVerify is set.
+	Checksum string
+
+	// InsecureSkipVerify allows installing the release without a Checksum.
+	// The download is then installed without any verification.
+	InsecureSkipVerify bool
+}
+
+// Updater provides the releases for the self-update command.
+type Updater interface {
+	// Latest returns the latest release for the running platform.
+	Latest(ctx context.Context) (*Release, error)
+}
+
+// Downloader may be implemented by an Updater to download releases itself
+// instead of fetching Release.URL with an HTTP GET request. size may be -1
+// if it is unknown.
+type Downloader interface {
+	Download(ctx context.Context, release *Release) (body io.ReadCloser, size int64, err error)
+}
+
+// ErrChecksumMismatch is returned if a downloaded release doesn't match its
+// Release.Checksum.
+var ErrChecksumMismatch = errors.New("psubcommands: checksum mismatch")
+
+// ErrNoChecksum is returned if a release has no Checksum and
+// Release.InsecureSkipVerify isn't set.
+var ErrNoChecksum = errors.New("psubcommands: release has no checksum")
+
+// compareVersions compares two versions like "v1.2.3" or "1.2.3-rc.1" and
+// returns -1, 0 or +1. Pre-releases are lower than the release itself.
+func compareVersions(a, b string) int {
+	split := func(v string) ([]string, string) {
+		v = strings.TrimPrefix(v, "v")
+		v, _, _ = strings.Cut(v, "+")
+		v, pre, _ := strings.Cut(v, "-")
+		return strings.Split(v, "."), pre
+	}
+	partsA, preA := split(a)
+	partsB, preB := split(b)
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return comparePrerelease(preA, preB)
+}
+
+// comparePrerelease compares the pre-release versions a and b like
+// compareVersions. Identifiers are compared from left to right, numerically
+// if both are numeric, numeric ones have a lower precedence than others. A
+// shorter list of otherwise equal identifiers has a lower precedence.
+func comparePrerelease(a, b string) int {
+	idsA, idsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(idsA) && i < len(idsB); i++ {
+		x, errX := strconv.ParseUint(idsA[i], 10, 64)
+		y, errY := strconv.ParseUint(idsB[i], 10, 64)
+		switch {
+		case errX == nil && errY == nil:
+			if x != y {
+				return cmpOrder(x < y)
+			}
+		case errX == nil:
+			return -1
+		case errY == nil:
+			return 1
+		case idsA[i] != idsB[i]:
+			return cmpOrder(idsA[i] < idsB[i])
+		}
+	}
+	if len(idsA) == len(idsB) {
+		return 0
+	}
+	return cmpOrder(len(idsA) < len(idsB))
+}
+
+// cmpOrder returns -1 if less is true and 1 otherwise.
+func cmpOrder(less bool) int {
+	if less {
+		return -1
+	}
+	return 1
+}
+
+type selfUpdateCommand struct {
+	c       *Commander
+	updater Updater
+}
+
+// Name of this command.
+func (*selfUpdateCommand) Name() string { return "self-update" }
+
+// Synopsis returns a short description of this command.
+func (s *selfUpdateCommand) Synopsis() string {
+	return s.c.tr("update this program to the latest release")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (s *selfUpdateCommand) SetFlags(f *pflag.FlagSet) {
+	f.Bool("check", false, s.c.tr("only check whether an update is available"))
+	f.Bool("force", false, s.c.tr("install the latest release even if it isn't newer"))
+}
+
+// Execute executs this command and returns it's ExitStatus.
+func (s *selfUpdateCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	c := s.c
+	streams := Streams(ctx)
+	check, _ := f.GetBool("check")
+	force, _ := f.GetBool("force")
+
+	release, err := s.updater.Latest(ctx)
+	if err != nil {
+		fmt.Fprintf(streams.Err, c.tr("Failed to find the latest release: %s\n"), err)
+		return ExitFailure
+	}
+
+	current, _, _ := c.BuildInfo()
+	newer := current != "" && compareVersions(release.Version, current) > 0
+	if check {
+		// --force doesn't affect whether an update is available.
+		switch {
+		case current == "":
+			fmt.Fprintf(streams.Out, c.tr("Unknown version of this build, the latest release is %s\n"), release.Version)
+		case newer:
+			fmt.Fprintf(streams.Out, c.tr("Update available: %s\n"), release.Version)
+		default:
+			fmt.Fprintf(streams.Out, c.tr("Already up to date (%s)\n"), current)
+		}
+		return ExitSuccess
+	}
+
+	switch {
+	case current == "" && !force:
+		fmt.Fprintf(streams.Err, c.tr("Unknown version of this build, use --force to install %s\n"), release.Version)
+		return ExitFailure
+	case current != "" && !newer && !force:
+		fmt.Fprintf(streams.Out, c.tr("Already up to date (%s)\n"), current)
+		return ExitSuccess
+	}
+
+	if err := s.install(ctx, release); err != nil {
+		fmt.Fprintf(streams.Err, c.tr("Failed to update to %s: %s\n"), release.Version, err)
+		return StatusFromError(err)
+	}
+	fmt.Fprintf(streams.Out, c.tr("Updated to %s\n"), release.Version)
+	return ExitSuccess
+}
+
+// install downloads release and replaces the running executable with it.
+func (s *selfUpdateCommand) install(ctx context.Context, release *Release) error {
+	if release.Checksum == "" && !release.InsecureSkipVerify {
+		return ErrNoChecksum
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	body, size, err := s.download(ctx, release)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	progress := StartProgress(ctx, fmt.Sprintf(s.c.tr("Downloading %s"), release.Version), int(size))
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash, progressWriter{progress}), body)
+	progress.Done("")
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if release.Checksum != "" && !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), release.Checksum) {
+		return ErrChecksumMismatch
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return replaceExecutable(tmp.Name(), exe)
+}
+
+// download opens the binary of release.
+func (s *selfUpdateCommand) download(ctx context.Context, release *Release) (io.ReadCloser, int64, error) {
+	if d, ok := s.updater.(Downloader); ok {
+		return d.Download(ctx, release)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, release.URL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("GET %s: %s", release.URL, resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// replaceExecutable moves the file src to exe. Systems refusing to replace a
+// running executable, like Windows, still allow renaming it, so the old
+// executable is moved aside first if the rename fails.
+func replaceExecutable(src, exe string) error {
+	if err := os.Rename(src, exe); err == nil {
+		return nil
+	}
+
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(src, exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	// Removing fails while the old executable is running on Windows.
+	os.Remove(old)
+	return nil
+}
+
+// progressWriter reports the bytes written to it to a Progress.
+type progressWriter struct{ p *Progress }
+
+func (w progressWriter) Write(b []byte) (int, error) {
+	w.p.Add(len(b))
+	return len(b), nil
+}
+
+// RegisterSelfUpdateCommand registers the "self-update" command to the
+// specified group. It asks updater for the latest release, compares its
+// version with the version of the program (see BuildInfo) and replaces the
+// running executable with the verified download.
+func (c *Commander) RegisterSelfUpdateCommand(group string, updater Updater) {
+	c.Register(group, &selfUpdateCommand{c: c, updater: updater})
+}
+
+// RegisterSelfUpdateCommand registers the self-update command on the DefaultCommander.
+func RegisterSelfUpdateCommand(group string, updater Updater) {
+	DefaultCommander.RegisterSelfUpdateCommand(group, updater)
+}
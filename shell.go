@@ -0,0 +1,207 @@
+package psubcommands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// DefaultHistorySize is the number of lines kept in the shell history.
+const DefaultHistorySize = 1000
+
+// Shell runs an interactive shell reading subcommand invocations from Input
+// until "exit" or end of input. If Input and Output are terminals, lines can
+// be edited, previous lines recalled with the arrow keys and command names
+// and flags completed with tab. The history is persisted to HistoryFile if set.
+// Shell returns the ExitStatus of the last executed command.
+func (c *Commander) Shell(ctx context.Context, args ...interface{}) ExitStatus {
+	prompt := filepath.Base(c.name) + "> "
+
+	readLine := c.plainLineReader(prompt)
+	if in, ok := c.Input.(*os.File); ok && isTerminal(in) && isTerminal(c.Output) {
+		readLine = c.terminalLineReader(in, prompt)
+	}
+
+	status := ExitSuccess
+	for ctx.Err() == nil {
+		line, err := readLine()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(c.Error, c.tr("Failed to read input: %s\n"), err)
+			}
+			break
+		}
+
+		argv, err := splitWords(line)
+		if err != nil {
+			fmt.Fprintf(c.Error, "%s\n", err)
+			status = ExitUsageError
+			continue
+		}
+		if len(argv) == 0 {
+			continue
+		}
+		if argv[0] == "exit" || argv[0] == "quit" {
+			break
+		}
+
+		status = c.dispatch(ctx, argv, args...)
+	}
+	return status
+}
+
+// plainLineReader reads lines from Input without any line editing.
+func (c *Commander) plainLineReader(prompt string) func() (string, error) {
+	r := bufio.NewReader(c.Input)
+	return func() (string, error) {
+		fmt.Fprint(c.Output, prompt)
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
+
+// terminalLineReader reads lines from the terminal in with line editing,
+// history and tab completion.
+func (c *Commander) terminalLineReader(in *os.File, prompt string) func() (string, error) {
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, c.Output}, prompt)
+	if c.HistoryFile != "" {
+		t.History = loadHistory(c.HistoryFile, DefaultHistorySize)
+	}
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return c.completeLine(line, pos)
+	}
+
+	return func() (string, error) {
+		state, err := term.MakeRaw(int(in.Fd()))
+		if err != nil {
+			return "", err
+		}
+		// Commands are executed in cooked mode.
+		defer term.Restore(int(in.Fd()), state)
+
+		if width, height, err := term.GetSize(int(in.Fd())); err == nil {
+			t.SetSize(width, height)
+		}
+		return t.ReadLine()
+	}
+}
+
+// completeLine completes the word in front of pos in line as far as all
+// completion candidates agree.
+func (c *Commander) completeLine(line string, pos int) (string, int, bool) {
+	before := line[:pos]
+	words := strings.Fields(before)
+	if before == "" || strings.HasSuffix(before, " ") {
+		words = append(words, "")
+	}
+
+	candidates, _ := c.complete(words)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	current := words[len(words)-1]
+	if len(prefix) <= len(current) {
+		return "", 0, false
+	}
+	if len(candidates) == 1 {
+		prefix += " "
+	}
+
+	completed := before[:len(before)-len(current)] + prefix
+	return completed + line[pos:], len(completed), true
+}
+
+// fileHistory is a term.History persisting all lines to a file.
+type fileHistory struct {
+	path    string
+	max     int
+	entries []string
+}
+
+func loadHistory(path string, max int) *fileHistory {
+	h := &fileHistory{path: path, max: max}
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				h.entries = append(h.entries, line)
+			}
+		}
+	}
+	if len(h.entries) > max {
+		h.entries = h.entries[len(h.entries)-max:]
+	}
+	return h
+}
+
+// Add appends entry to the history and the history file.
+func (h *fileHistory) Add(entry string) {
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.max {
+		h.entries = h.entries[1:]
+	}
+
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, entry)
+}
+
+// Len returns the number of entries.
+func (h *fileHistory) Len() int { return len(h.entries) }
+
+// At returns the entry at idx, with 0 being the most recent one.
+func (h *fileHistory) At(idx int) string { return h.entries[len(h.entries)-1-idx] }
+
+type shellCommand Commander
+
+// Name of this command.
+func (*shellCommand) Name() string { return "shell" }
+
+// Synopsis returns a short description of this command.
+func (s *shellCommand) Synopsis() string {
+	return (*Commander)(s).tr("start an interactive shell")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*shellCommand) SetFlags(*pflag.FlagSet) {}
+
+// Execute executs this command and returns it's ExitStatus.
+func (s *shellCommand) Execute(ctx context.Context, _ *pflag.FlagSet, args ...interface{}) ExitStatus {
+	return (*Commander)(s).Shell(ctx, args...)
+}
+
+// RegisterShellCommand registers the "shell" command starting an interactive
+// shell to the specified group.
+func (c *Commander) RegisterShellCommand(group string) { c.Register(group, (*shellCommand)(c)) }
+
+// RegisterShellCommand registers the shell command on the DefaultCommander.
+func RegisterShellCommand(group string) { DefaultCommander.RegisterShellCommand(group) }
@@ -0,0 +1,71 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultShutdownTimeout is used if Commander.ShutdownTimeout is zero.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// OnShutdown registers fn to be called after the command was executed or,
+// when using ExecuteWithSignals, before the process is forced to exit.
+// Hooks are called in reverse order of registration and share a context
+// expiring after ShutdownTimeout. Each hook is called once, so commands may
+// register cleanup of the resources they acquire while executing.
+func (c *Commander) OnShutdown(fn func(ctx context.Context) error) { c.addShutdownHook(fn) }
+
+// shutdownHook is a hook registered with OnShutdown. Hooks are stored by
+// pointer so they can be found again for removal.
+type shutdownHook struct {
+	fn func(ctx context.Context) error
+}
+
+// addShutdownHook registers fn like OnShutdown and returns a function
+// removing it again if it didn't run yet.
+func (c *Commander) addShutdownHook(fn func(ctx context.Context) error) (remove func()) {
+	h := &shutdownHook{fn: fn}
+	c.shutdownMu.Lock()
+	defer c.shutdownMu.Unlock()
+	c.onShutdown = append(c.onShutdown, h)
+	return func() {
+		c.shutdownMu.Lock()
+		defer c.shutdownMu.Unlock()
+		for i, hook := range c.onShutdown {
+			if hook == h {
+				c.onShutdown = append(c.onShutdown[:i:i], c.onShutdown[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// shutdown runs and removes all hooks registered with OnShutdown.
+// Errors returned by the hooks are written to Error.
+func (c *Commander) shutdown(ctx context.Context) {
+	c.shutdownMu.Lock()
+	hooks := c.onShutdown
+	c.onShutdown = nil
+	c.shutdownMu.Unlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	timeout := c.ShutdownTimeout
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	// Hooks must run even if ctx was cancelled by a signal.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			fmt.Fprintf(c.Error, c.tr("Shutdown failed: %s\n"), err)
+		}
+	}
+}
+
+// OnShutdown registers fn to be called on shutdown of the DefaultCommander.
+func OnShutdown(fn func(ctx context.Context) error) { DefaultCommander.OnShutdown(fn) }
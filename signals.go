@@ -0,0 +1,60 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// DefaultSignals are the signals handled by ExecuteWithSignals if none are
+// specified. On Windows os.Interrupt is delivered for both CTRL_C_EVENT and
+// CTRL_BREAK_EVENT and syscall.SIGTERM when the console is closed.
+var DefaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// ExecuteWithSignals works like Execute but cancels the context passed to the
+// subcommand when one of signals is received. A second signal forces the
+// process to exit with ExitFailure after running the hooks registered with
+// OnShutdown.
+// If signals is empty DefaultSignals will be used.
+func (c *Commander) ExecuteWithSignals(ctx context.Context, signals []os.Signal, args ...interface{}) ExitStatus {
+	if len(signals) == 0 {
+		signals = DefaultSignals
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case sig := <-ch:
+			fmt.Fprintf(c.Error, c.tr("Received %s again, exiting\n"), sig)
+			c.shutdown(ctx)
+			os.Exit(int(ExitFailure))
+		case <-done:
+		}
+	}()
+
+	return c.Execute(ctx, args...)
+}
+
+// ExecuteWithSignals works like Execute on the DefaultCommander but cancels the
+// context passed to the subcommand when one of signals is received.
+func ExecuteWithSignals(ctx context.Context, signals []os.Signal, args ...interface{}) ExitStatus {
+	return DefaultCommander.ExecuteWithSignals(ctx, signals, args...)
+}
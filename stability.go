@@ -0,0 +1,125 @@
+package psubcommands
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Stability describes how mature a command is.
+type Stability int
+
+const (
+	// Stable commands are listed and executed without restrictions.
+	Stable Stability = iota
+	// Beta commands are marked as such in help output.
+	Beta
+	// Experimental commands are hidden and refuse to run unless
+	// experimental commands are enabled.
+	Experimental
+	// Alpha commands are treated like Experimental ones.
+	Alpha
+)
+
+// String returns the lower case name of the stability level.
+func (s Stability) String() string {
+	switch s {
+	case Beta:
+		return "beta"
+	case Experimental:
+		return "experimental"
+	case Alpha:
+		return "alpha"
+	}
+	return "stable"
+}
+
+// gated reports whether commands of this level require enabling.
+func (s Stability) gated() bool { return s >= Experimental }
+
+// StabilityLeveler may be implemented by a Command to declare its stability.
+// Commands not implementing it are Stable.
+type StabilityLeveler interface {
+	Stability() Stability
+}
+
+// ExperimentalFlag is the name of the flag registered by EnableExperimentalFlag.
+const ExperimentalFlag = "enable-experimental"
+
+// EnableExperimentalFlag registers the top-level flag --enable-experimental
+// which enables experimental and alpha commands.
+func (c *Commander) EnableExperimentalFlag() {
+	c.topFlags.Bool(ExperimentalFlag, false, c.tr("enable experimental commands"))
+}
+
+// EnableExperimentalFlag registers the --enable-experimental flag on the DefaultCommander.
+func EnableExperimentalFlag() { DefaultCommander.EnableExperimentalFlag() }
+
+// stability returns the stability level of cmd.
+// Lazily registered commands are not constructed to determine it, so they
+// are considered stable until they are executed or their help is shown.
+func stability(cmd Command) Stability {
+	if s, ok := cmd.(StabilityLeveler); ok {
+		return s.Stability()
+	}
+	return Stable
+}
+
+var nonEnvChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// experimentalEnv returns the environment variable enabling experimental commands.
+func (c *Commander) experimentalEnv() string {
+	if c.ExperimentalEnv != "" {
+		return c.ExperimentalEnv
+	}
+	name := strings.ToUpper(filepath.Base(c.name))
+	return nonEnvChars.ReplaceAllString(name, "_") + "_ENABLE_EXPERIMENTAL"
+}
+
+// experimentalEnabled reports whether experimental commands may be used.
+func (c *Commander) experimentalEnabled() bool {
+	if enabled, _ := c.topFlags.GetBool(ExperimentalFlag); enabled {
+		return true
+	}
+	return envTrue(c.getenv(c.experimentalEnv()))
+}
+
+// visibleCommands returns cmds without the experimental commands, unless
+// experimental commands are enabled.
+func (c *Commander) visibleCommands(cmds []Command) []Command {
+	if c.experimentalEnabled() {
+		return cmds
+	}
+	visible := []Command{}
+	for _, cmd := range cmds {
+		if !stability(cmd).gated() {
+			visible = append(visible, cmd)
+		}
+	}
+	return visible
+}
+
+// synopsis returns the synopsis of cmd, followed by its stability level
+// unless it is stable.
+func (c *Commander) synopsis(cmd Command) string {
+	if s := stability(cmd); s != Stable {
+		return fmt.Sprintf("%s [%s]", cmd.Synopsis(), c.tr(s.String()))
+	}
+	return cmd.Synopsis()
+}
+
+// checkStability refuses to run experimental commands unless enabled.
+func (c *Commander) checkStability(cmd Command) ExitStatus {
+	s := stability(unwrap(cmd))
+	if !s.gated() || c.experimentalEnabled() {
+		return ExitSuccess
+	}
+
+	enable := c.experimentalEnv() + "=1"
+	if c.topFlags.Lookup(ExperimentalFlag) != nil {
+		enable = fmt.Sprintf(c.tr("--%s or %s"), ExperimentalFlag, enable)
+	}
+	fmt.Fprintf(c.Error, c.tr("Subcommand %s is %s, enable it with %s\n"), cmd.Name(), c.tr(s.String()), enable)
+	return ExitUsageError
+}
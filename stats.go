@@ -0,0 +1,279 @@
+package psubcommands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// StatsUploadInterval is the minimum time between two calls of the
+// StatsUploader passed to EnableStats, whether they succeeded or not.
+var StatsUploadInterval = 24 * time.Hour
+
+// StatsUploadTimeout bounds a single call of the StatsUploader.
+var StatsUploadTimeout = 10 * time.Second
+
+// statsLockTimeout bounds waiting for other processes updating the
+// statistics file.
+const statsLockTimeout = 2 * time.Second
+
+// CommandStats aggregates the invocations of a single command.
+type CommandStats struct {
+	Count         int           `json:"count" yaml:"count"`
+	Failures      int           `json:"failures" yaml:"failures"`
+	TotalDuration time.Duration `json:"total_duration" yaml:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration" yaml:"max_duration"`
+	LastUsed      time.Time     `json:"last_used" yaml:"last_used"`
+}
+
+// UsageStats holds the aggregated invocations of all commands since Since.
+type UsageStats struct {
+	Since             time.Time                `json:"since" yaml:"since"`
+	LastUpload        time.Time                `json:"last_upload,omitempty" yaml:"last_upload,omitempty"`
+	LastUploadAttempt time.Time                `json:"last_upload_attempt,omitempty" yaml:"last_upload_attempt,omitempty"`
+	Commands          map[string]*CommandStats `json:"commands" yaml:"commands"`
+}
+
+// StatsUploader sends the aggregated usage statistics, e.g. to a service
+// collecting the usage of all installations.
+type StatsUploader func(ctx context.Context, stats *UsageStats) error
+
+// EnableStats opts in to recording the invocation count and duration of
+// every command executed by the Commander, aggregated in the JSON file at
+// path. Nothing but command names, exit statuses and durations is
+// recorded. If upload is not nil it is called with the statistics at most
+// once per StatsUploadInterval, failed uploads are retried after the next
+// interval. Setting the DO_NOT_TRACK environment variable disables
+// recording. Failures are logged to Logger only.
+func (c *Commander) EnableStats(path string, upload StatsUploader) {
+	c.statsFile = path
+	var mu sync.Mutex
+	c.OnCommandEnd(func(ctx context.Context, ev *CommandEvent) {
+		if envTrue(c.getenv("DO_NOT_TRACK")) {
+			return
+		}
+		// The command may have ended because ctx was cancelled.
+		ctx = context.WithoutCancel(ctx)
+		mu.Lock()
+		due, err := c.recordStats(ctx, ev, upload != nil)
+		mu.Unlock()
+		if err != nil {
+			c.debug(ctx, "failed to record usage statistics", "error", err)
+			return
+		}
+		if due != nil {
+			c.uploadStats(ctx, due, upload, &mu)
+		}
+	})
+}
+
+// EnableStats enables usage statistics on the DefaultCommander.
+func EnableStats(path string, upload StatsUploader) { DefaultCommander.EnableStats(path, upload) }
+
+// Stats returns the usage statistics recorded since EnableStats was called.
+func (c *Commander) Stats() (*UsageStats, error) {
+	if c.statsFile == "" {
+		return nil, errors.New(c.tr("usage statistics are not enabled"))
+	}
+	return readStats(c.statsFile)
+}
+
+// recordStats adds ev to the statistics file. If canUpload is true and the
+// upload is due, it returns the statistics to upload and records the
+// attempt, so concurrent processes don't upload them again.
+func (c *Commander) recordStats(ctx context.Context, ev *CommandEvent, canUpload bool) (*UsageStats, error) {
+	var due *UsageStats
+	err := updateStats(ctx, c.statsFile, func(stats *UsageStats) {
+		s, ok := stats.Commands[ev.Name]
+		if !ok {
+			s = &CommandStats{}
+			stats.Commands[ev.Name] = s
+		}
+		s.Count++
+		if ev.Status != ExitSuccess {
+			s.Failures++
+		}
+		s.TotalDuration += ev.Duration
+		s.MaxDuration = max(s.MaxDuration, ev.Duration)
+		s.LastUsed = ev.Start
+
+		last := stats.LastUpload
+		if stats.LastUploadAttempt.After(last) {
+			last = stats.LastUploadAttempt
+		}
+		if canUpload && time.Since(last) >= StatsUploadInterval {
+			stats.LastUploadAttempt = time.Now()
+			due = stats
+		}
+	})
+	return due, err
+}
+
+// uploadStats calls upload with stats, bounded by StatsUploadTimeout, and
+// records a successful upload. mu is held while updating the file.
+func (c *Commander) uploadStats(ctx context.Context, stats *UsageStats, upload StatsUploader, mu *sync.Mutex) {
+	ctx, cancel := context.WithTimeout(ctx, StatsUploadTimeout)
+	defer cancel()
+	if err := upload(ctx, stats); err != nil {
+		c.debug(ctx, "failed to upload usage statistics", "error", err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	err := updateStats(ctx, c.statsFile, func(stats *UsageStats) { stats.LastUpload = time.Now() })
+	if err != nil {
+		c.debug(ctx, "failed to record usage statistics upload", "error", err)
+	}
+}
+
+// updateStats calls update with the statistics file at path and writes
+// them back. The file is locked against other processes meanwhile.
+func updateStats(ctx context.Context, path string, update func(stats *UsageStats)) error {
+	unlock, err := lockStats(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	stats, err := readStats(path)
+	if err != nil {
+		return err
+	}
+	update(stats)
+	return writeStats(path, stats)
+}
+
+// lockStats locks the statistics file at path, waiting up to
+// statsLockTimeout for other processes. A separate lock file is used as
+// the statistics file is replaced on every write.
+func lockStats(ctx context.Context, path string) (func(), error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, statsLockTimeout)
+	defer cancel()
+	for {
+		locked, err := tryLock(file)
+		switch {
+		case err != nil:
+			file.Close()
+			return nil, err
+		case locked:
+			// Closing the file releases the lock.
+			return func() { file.Close() }, nil
+		}
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", path, ctx.Err())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// readStats reads the statistics file at path. A missing file results in
+// empty statistics.
+func readStats(path string) (*UsageStats, error) {
+	stats := &UsageStats{Since: time.Now(), Commands: map[string]*CommandStats{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if stats.Commands == nil {
+		stats.Commands = map[string]*CommandStats{}
+	}
+	return stats, nil
+}
+
+func writeStats(path string, stats *UsageStats) error {
+	return replaceFile(path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	})
+}
+
+type statsCommand Commander
+
+// Name of this command.
+func (*statsCommand) Name() string { return "stats" }
+
+// Synopsis returns a short description of this command.
+func (s *statsCommand) Synopsis() string {
+	return (*Commander)(s).tr("show usage statistics of the commands")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (s *statsCommand) SetFlags(f *pflag.FlagSet) {
+	f.Bool("reset", false, (*Commander)(s).tr("delete the recorded statistics"))
+}
+
+// OutputFormats returns the supported output formats.
+func (*statsCommand) OutputFormats() []string { return nil }
+
+// Execute executs this command and returns it's ExitStatus.
+func (s *statsCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	c := (*Commander)(s)
+	streams := Streams(ctx)
+	if reset, _ := f.GetBool("reset"); reset {
+		if c.statsFile == "" {
+			fmt.Fprintln(streams.Err, c.tr("Usage statistics are not enabled"))
+			return ExitFailure
+		}
+		if err := os.Remove(c.statsFile); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(streams.Err, c.tr("Failed to reset usage statistics: %s\n"), err)
+			return ExitFailure
+		}
+		return ExitSuccess
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		fmt.Fprintf(streams.Err, c.tr("Failed to read usage statistics: %s\n"), err)
+		return ExitFailure
+	}
+	if format := OutputFormat(ctx); format != DefaultOutputFormat {
+		if err := Render(ctx, format, stats); err != nil {
+			fmt.Fprintln(streams.Err, err)
+			return ExitFailure
+		}
+		return ExitSuccess
+	}
+
+	names := sortedKeys(stats.Commands)
+	sort.SliceStable(names, func(i, j int) bool { return stats.Commands[names[i]].Count > stats.Commands[names[j]].Count })
+	t := NewTable(ctx, c.tr("COMMAND"), c.tr("COUNT"), c.tr("FAILURES"), c.tr("AVG"), c.tr("MAX"), c.tr("LAST USED"))
+	for _, name := range names {
+		cs := stats.Commands[name]
+		avg := cs.TotalDuration / time.Duration(max(cs.Count, 1))
+		t.Append(name, cs.Count, cs.Failures, avg.Round(time.Millisecond), cs.MaxDuration.Round(time.Millisecond), cs.LastUsed.Format(time.DateTime))
+	}
+	if err := t.Render(); err != nil {
+		fmt.Fprintln(streams.Err, err)
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// RegisterStatsCommand registers the "stats" command showing the usage
+// statistics recorded with EnableStats to the specified group.
+func (c *Commander) RegisterStatsCommand(group string) { c.Register(group, (*statsCommand)(c)) }
+
+// RegisterStatsCommand registers the stats command on the DefaultCommander.
+func RegisterStatsCommand(group string) { DefaultCommander.RegisterStatsCommand(group) }
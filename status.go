@@ -0,0 +1,113 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// ExitCoder is implemented by errors carrying a specific exit code.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitCode returns the status as int. It implements ExitCoder.
+func (s ExitStatus) ExitCode() int { return int(s) }
+
+// ExitError is an error carrying an arbitrary exit code, e.g. 3 for
+// "partial success".
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Exitf returns an *ExitError with code and a formatted message.
+func Exitf(code int, format string, a ...interface{}) *ExitError {
+	return &ExitError{Code: code, Err: fmt.Errorf(format, a...)}
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode returns the exit code of the error.
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// errorStatuses maps errors to exit statuses, see RegisterErrorStatus.
+var errorStatuses = []func(err error) (ExitStatus, bool){
+	errorIs(context.Canceled, ExitCancelled),
+	errorIs(context.DeadlineExceeded, ExitTimeout),
+	errorIs(os.ErrPermission, ExitPermissionDenied),
+}
+
+func errorIs(target error, status ExitStatus) func(error) (ExitStatus, bool) {
+	return func(err error) (ExitStatus, bool) { return status, errors.Is(err, target) }
+}
+
+// RegisterErrorStatus makes StatusFromError return status for errors
+// matching target according to errors.Is. Later registrations take
+// precedence. By default context.Canceled maps to ExitCancelled,
+// context.DeadlineExceeded to ExitTimeout and os.ErrPermission to
+// ExitPermissionDenied.
+func RegisterErrorStatus(target error, status ExitStatus) {
+	RegisterErrorFunc(errorIs(target, status))
+}
+
+// RegisterErrorFunc registers fn to map errors to exit statuses, e.g. by
+// their type. fn returns false for errors it doesn't handle.
+func RegisterErrorFunc(fn func(err error) (ExitStatus, bool)) {
+	errorStatuses = append(errorStatuses, fn)
+}
+
+// StatusFromError converts err into an ExitStatus. A nil error results in
+// ExitSuccess, errors implementing ExitCoder, like *exec.ExitError, in their
+// exit code and errors registered with RegisterErrorStatus or
+// RegisterErrorFunc in the registered status. All other errors result in
+// ExitFailure.
+func StatusFromError(err error) ExitStatus {
+	if err == nil {
+		return ExitSuccess
+	}
+
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return ExitStatus(coder.ExitCode())
+	}
+	for i := len(errorStatuses) - 1; i >= 0; i-- {
+		if status, ok := errorStatuses[i](err); ok {
+			return status
+		}
+	}
+	return ExitFailure
+}
+
+// Worst returns the worse of the statuses a and b: ExitSuccess only if both
+// are successful, otherwise the higher status. It can be used to combine the
+// results of multiple commands.
+func Worst(a, b ExitStatus) ExitStatus {
+	switch {
+	case a == ExitSuccess:
+		return b
+	case b == ExitSuccess || a > b:
+		return a
+	}
+	return b
+}
+
+// UsageErrorf prints the formatted message followed by the usage of the
+// command owning f to the Commanders Error and returns ExitUsageError.
+// f must be the FlagSet passed to Command.Execute.
+func UsageErrorf(f *pflag.FlagSet, format string, a ...interface{}) ExitStatus {
+	fmt.Fprintf(f.Output(), format+"\n\n", a...)
+	f.Usage()
+	return ExitUsageError
+}
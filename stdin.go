@@ -0,0 +1,53 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// StdinArg is the positional argument standing for standard input.
+const StdinArg = "-"
+
+// ErrInputTooLarge is returned by ReadStdin if the input exceeds the limit.
+var ErrInputTooLarge = errors.New("psubcommands: input too large")
+
+// StdinPiped reports whether the In stream of the current command is piped
+// or redirected rather than connected to a terminal.
+func StdinPiped(ctx context.Context) bool {
+	file, ok := Streams(ctx).In.(*os.File)
+	if !ok {
+		return Streams(ctx).In != nil
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// ReadStdin reads the In stream of the current command until EOF. If limit
+// is positive and the input is longer, ErrInputTooLarge is returned.
+func ReadStdin(ctx context.Context, limit int64) ([]byte, error) {
+	in := Streams(ctx).In
+	if limit <= 0 {
+		return io.ReadAll(in)
+	}
+	data, err := io.ReadAll(io.LimitReader(in, limit+1))
+	if err != nil {
+		return data, err
+	}
+	if int64(len(data)) > limit {
+		return data[:limit], fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, limit)
+	}
+	return data, nil
+}
+
+// OpenInput opens the file named by a positional argument for reading. The
+// argument "-" stands for the In stream of the current command, which is
+// not closed by the returned ReadCloser. See ArgSpec.Stdin.
+func OpenInput(ctx context.Context, name string) (io.ReadCloser, error) {
+	if name == StdinArg {
+		return io.NopCloser(Streams(ctx).In), nil
+	}
+	return os.Open(name)
+}
@@ -0,0 +1,37 @@
+package psubcommands
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// IOStreams holds the standard streams a command should use instead of
+// os.Stdin, os.Stdout and os.Stderr.
+type IOStreams struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+// IOStreams returns the streams of this Commander.
+func (c *Commander) IOStreams() *IOStreams {
+	return &IOStreams{
+		In:  c.Input,
+		Out: c.Output,
+		Err: c.Error,
+	}
+}
+
+// Streams returns the IOStreams of the Commander executing the current
+// command. If ctx doesn't belong to a Commander the process streams are returned.
+func Streams(ctx context.Context) *IOStreams {
+	if s, ok := ctx.Value(streamsKey).(*IOStreams); ok {
+		return s
+	}
+	return &IOStreams{
+		In:  os.Stdin,
+		Out: os.Stdout,
+		Err: os.Stderr,
+	}
+}
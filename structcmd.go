@@ -0,0 +1,115 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Runner is implemented by structs used with NewStructCommand.
+type Runner interface {
+	// Run executes the command with the remaining positional arguments.
+	// All tagged fields are populated before Run is called.
+	Run(ctx context.Context, args []string) ExitStatus
+}
+
+type structCommand struct {
+	name     string
+	synopsis string
+	runner   Runner
+	defaults reflect.Value
+}
+
+// NewStructCommand returns a Command whose flags are defined by the struct
+// tags of runner, which must be a pointer to a struct. Every exported field
+// tagged with `flag:"name,shorthand,usage"` is registered as flag, using the
+// current field value as default. Shorthand and usage are optional.
+//
+// Supported field types are string, bool, int, int64, uint, float64,
+// time.Duration, []string, []int and any type implementing pflag.Value.
+// NewStructCommand panics if runner isn't a pointer to a struct or contains
+// tagged fields of unsupported types.
+func NewStructCommand(name, synopsis string, runner Runner) Command {
+	v := reflect.ValueOf(runner)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("psubcommands: %s: runner must be a pointer to a struct, got %T", name, runner))
+	}
+
+	defaults := reflect.New(v.Elem().Type()).Elem()
+	defaults.Set(v.Elem())
+
+	cmd := &structCommand{name: name, synopsis: synopsis, runner: runner, defaults: defaults}
+	cmd.SetFlags(pflag.NewFlagSet(name, pflag.ContinueOnError))
+	return cmd
+}
+
+func (c *structCommand) Name() string     { return c.name }
+func (c *structCommand) Synopsis() string { return c.synopsis }
+
+func (c *structCommand) SetFlags(f *pflag.FlagSet) {
+	v := reflect.ValueOf(c.runner).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("flag")
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+
+		parts := strings.SplitN(tag, ",", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+		name, shorthand, usage := parts[0], parts[1], parts[2]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		// Reset the field so every FlagSet starts with the original defaults.
+		v.Field(i).Set(c.defaults.Field(i))
+		if err := bindField(f, v.Field(i), name, shorthand, usage); err != nil {
+			panic(fmt.Sprintf("psubcommands: %s: field %s: %s", c.name, field.Name, err))
+		}
+	}
+}
+
+func (c *structCommand) Execute(ctx context.Context, f *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	return c.runner.Run(ctx, f.Args())
+}
+
+func bindField(f *pflag.FlagSet, v reflect.Value, name, shorthand, usage string) error {
+	p := v.Addr().Interface()
+	if value, ok := p.(pflag.Value); ok {
+		f.VarP(value, name, shorthand, usage)
+		return nil
+	}
+
+	switch p := p.(type) {
+	case *string:
+		f.StringVarP(p, name, shorthand, *p, usage)
+	case *bool:
+		f.BoolVarP(p, name, shorthand, *p, usage)
+	case *int:
+		f.IntVarP(p, name, shorthand, *p, usage)
+	case *int64:
+		f.Int64VarP(p, name, shorthand, *p, usage)
+	case *uint:
+		f.UintVarP(p, name, shorthand, *p, usage)
+	case *float64:
+		f.Float64VarP(p, name, shorthand, *p, usage)
+	case *time.Duration:
+		f.DurationVarP(p, name, shorthand, *p, usage)
+	case *[]string:
+		f.StringSliceVarP(p, name, shorthand, *p, usage)
+	case *[]int:
+		f.IntSliceVarP(p, name, shorthand, *p, usage)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package psubcommands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnknownValueError reports a value which isn't one of the known values of
+// its kind, e.g. a misspelled resource name.
+type UnknownValueError struct {
+	// Kind describes the value, e.g. "region".
+	Kind  string
+	Value string
+	// Suggestions are known values similar to Value, best match first.
+	Suggestions []string
+}
+
+func (e *UnknownValueError) Error() string {
+	msg := fmt.Sprintf("unknown %s '%s'", e.Kind, e.Value)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean '%s'?", strings.Join(e.Suggestions, "' or '"))
+	}
+	return msg
+}
+
+// CheckValue returns nil if value is one of candidates and an
+// *UnknownValueError suggesting similar candidates otherwise. Commands can
+// use it to validate arguments naming resources.
+func CheckValue(kind, value string, candidates []string) error {
+	if contains(candidates, value) {
+		return nil
+	}
+	return &UnknownValueError{Kind: kind, Value: value, Suggestions: Suggest(value, candidates)}
+}
+
+// Known returns a FlagValidator accepting the values returned by
+// candidates, which is called when the flag is validated. Unknown values
+// are reported with suggestions, see CheckValue.
+func Known(kind string, candidates func() []string) FlagValidator {
+	return func(value string) error { return CheckValue(kind, value, candidates()) }
+}
+
+// Suggest returns the candidates similar to value, most similar first.
+// Candidates are similar if they start with value or are within an edit
+// distance of a third of the length of value, at least one.
+func Suggest(value string, candidates []string) []string {
+	limit := max(len([]rune(value))/3, 1)
+
+	type match struct {
+		candidate string
+		distance  int
+	}
+	matches := []match{}
+	for _, candidate := range candidates {
+		d := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if d <= limit || (value != "" && strings.HasPrefix(candidate, value)) {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+
+	suggestions := make([]string, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.candidate
+	}
+	return suggestions
+}
+
+// editDistance returns the edit distance of a and b, counting insertions,
+// deletions, substitutions and transpositions of adjacent characters.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
@@ -0,0 +1,108 @@
+package psubcommands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// synopsisColumn is the column at which synopses start in overviews:
+// a tab, the padded name and four spaces.
+const synopsisColumn = 8 + 15 + 4
+
+// minSynopsisWidth is the minimum width a synopsis is wrapped to.
+const minSynopsisWidth = 20
+
+// overviewLine formats name and synopsis as a line of an overview. Only the
+// first line of a multi-line synopsis is shown, followed by an ellipsis. If w
+// is a terminal the synopsis is wrapped to its width, with continuation lines
+// aligned under the synopsis column.
+func overviewLine(w io.Writer, name, synopsis string) string {
+	synopsis = strings.TrimSpace(synopsis)
+	if i := strings.IndexByte(synopsis, '\n'); i >= 0 {
+		synopsis = strings.TrimSpace(synopsis[:i]) + "..."
+	}
+	if width := terminalWidth(w) - synopsisColumn; width >= minSynopsisWidth {
+		synopsis = wrap(synopsis, width, "\n"+strings.Repeat(" ", synopsisColumn))
+	}
+	return fmt.Sprintf("\t%-15s    %s\n", name, synopsis)
+}
+
+// terminalWidth returns the width of the terminal w or 0 if w isn't a terminal.
+func terminalWidth(w io.Writer) int {
+	if b, ok := w.(*widthBuffer); ok {
+		return b.width
+	}
+	file, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// wrap breaks s into lines of at most width characters, joined by sep.
+// Words longer than width are not broken.
+func wrap(s string, width int, sep string) string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) > width:
+			lines = append(lines, line)
+			line = word
+		default:
+			line += " " + word
+		}
+	}
+	return strings.Join(append(lines, line), sep)
+}
+
+// widthBuffer buffers output rendered for a terminal of the given width.
+type widthBuffer struct {
+	bytes.Buffer
+	width int
+}
+
+// helpKey identifies cached help output by command name, empty for the
+// overview, and the terminal width it was rendered for.
+type helpKey struct {
+	name  string
+	width int
+}
+
+// cachedHelp writes the help rendered by render to w, using the cached
+// output for name if CacheHelp is enabled.
+func (c *Commander) cachedHelp(w io.Writer, name string, render func(w io.Writer)) {
+	if !c.CacheHelp {
+		render(w)
+		return
+	}
+
+	key := helpKey{name: name, width: terminalWidth(w)}
+	c.mu.RLock()
+	out, ok := c.help[key]
+	c.mu.RUnlock()
+	if !ok {
+		buf := &widthBuffer{width: key.width}
+		render(buf)
+		out = buf.Bytes()
+
+		c.mu.Lock()
+		if c.help == nil {
+			c.help = map[helpKey][]byte{}
+		}
+		c.help[key] = out
+		c.mu.Unlock()
+	}
+	w.Write(out)
+}
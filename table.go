@@ -0,0 +1,128 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// tableGap is the number of spaces between the columns of a Table.
+	tableGap = 2
+	// minTableColumn is the width the last column of a Table is never
+	// truncated below.
+	minTableColumn = 8
+)
+
+// Table renders rows of cells as aligned columns. On a terminal the header
+// is highlighted and the last column is truncated to the width of the
+// terminal. Colors are disabled if the NO_COLOR environment variable is set
+// or TERM is dumb.
+type Table struct {
+	w      io.Writer
+	header []string
+	rows   [][]string
+	width  int
+	color  bool
+}
+
+// Table returns a Table writing to Out with the column titles header. The
+// Table has no header if none are given.
+func (s *IOStreams) Table(header ...string) *Table {
+	return newTable(s.Out, os.LookupEnv, header)
+}
+
+// NewTable returns a Table writing to the Out stream of the current command,
+// honoring its environment, see Streams and LookupEnv.
+func NewTable(ctx context.Context, header ...string) *Table {
+	return newTable(Streams(ctx).Out, func(key string) (string, bool) { return LookupEnv(ctx, key) }, header)
+}
+
+func newTable(w io.Writer, lookup EnvFunc, header []string) *Table {
+	_, noColor := lookup("NO_COLOR")
+	term, _ := lookup("TERM")
+	return &Table{
+		w:      w,
+		header: header,
+		width:  terminalWidth(w),
+		color:  isTerminal(w) && !noColor && term != "dumb" && enableVirtualTerminal(w),
+	}
+}
+
+// Append adds a row to t. Cells are formatted with fmt.Sprint.
+func (t *Table) Append(cells ...interface{}) *Table {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = fmt.Sprint(cell)
+	}
+	t.rows = append(t.rows, row)
+	return t
+}
+
+// Render writes the header and all rows of t.
+func (t *Table) Render() error {
+	widths := []int{}
+	measure := func(row []string) {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	measure(t.header)
+	for _, row := range t.rows {
+		measure(row)
+	}
+
+	if len(t.header) > 0 {
+		if err := t.writeRow(t.header, widths, t.color); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.rows {
+		if err := t.writeRow(row, widths, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) writeRow(row []string, widths []int, bold bool) error {
+	var b strings.Builder
+	column := 0
+	for i, cell := range row {
+		if i == len(row)-1 {
+			if max := t.width - column; t.width > 0 && max >= minTableColumn {
+				cell = truncate(cell, max)
+			}
+			b.WriteString(cell)
+			break
+		}
+		b.WriteString(cell)
+		pad := widths[i] - utf8.RuneCountInString(cell) + tableGap
+		b.WriteString(strings.Repeat(" ", pad))
+		column += widths[i] + tableGap
+	}
+
+	line := strings.TrimRight(b.String(), " ")
+	if bold {
+		line = "\x1b[1m" + line + "\x1b[0m"
+	}
+	_, err := fmt.Fprintln(t.w, line)
+	return err
+}
+
+// truncate shortens s to at most width characters, ending with an ellipsis.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,79 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoWorkdir is returned by Workdir if the context wasn't passed by a
+// Commander.
+var ErrNoWorkdir = errors.New("psubcommands: no temporary working directory in context")
+
+// tempWorkdir is a temporary directory created on first use.
+type tempWorkdir struct {
+	mu      sync.Mutex
+	c       *Commander
+	dir     string
+	err     error
+	removed bool
+
+	// unregister removes the OnShutdown hook removing dir.
+	unregister func()
+}
+
+// Workdir returns a temporary directory for the current command. It is
+// created on first use and removed with all its contents when the dispatch
+// of the command returns, or when the process is forced to exit by
+// ExecuteWithSignals, see OnShutdown. Subsequent calls during the same
+// invocation return the same directory.
+func Workdir(ctx context.Context) (string, error) {
+	w, ok := ctx.Value(workdirKey).(*tempWorkdir)
+	if !ok {
+		return "", ErrNoWorkdir
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.removed {
+		return "", ErrNoWorkdir
+	}
+	if w.dir == "" && w.err == nil {
+		w.dir, w.err = os.MkdirTemp("", filepath.Base(w.c.name)+"-")
+		if w.err == nil {
+			w.unregister = w.c.addShutdownHook(func(context.Context) error { return w.remove() })
+		}
+	}
+	return w.dir, w.err
+}
+
+// remove removes the directory, if it was created. Later calls of Workdir
+// fail with ErrNoWorkdir.
+func (w *tempWorkdir) remove() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.removed = true
+	if w.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(w.dir)
+}
+
+// removeWorkdir removes the temporary directory of the invocation ctx
+// belongs to. Failures are written to Error.
+func (c *Commander) removeWorkdir(ctx context.Context) {
+	if w, ok := ctx.Value(workdirKey).(*tempWorkdir); ok {
+		w.mu.Lock()
+		unregister := w.unregister
+		w.mu.Unlock()
+		if unregister != nil {
+			unregister()
+		}
+		if err := w.remove(); err != nil {
+			fmt.Fprintf(c.Error, c.tr("Failed to remove working directory: %s\n"), err)
+		}
+	}
+}
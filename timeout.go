@@ -0,0 +1,64 @@
+package psubcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Timeouter may be implemented by a Command to limit its execution time.
+// A Timeout of zero or less disables the deadline for this command.
+type Timeouter interface {
+	Timeout() time.Duration
+}
+
+// errCommandTimeout is the cause of the context of a command exceeding its
+// own timeout.
+var errCommandTimeout = errors.New("psubcommands: command timed out")
+
+// timeout returns the timeout for cmd. A Timeouter takes precedence over
+// the Commander wide Timeout.
+func (c *Commander) timeout(cmd Command) time.Duration {
+	if t, ok := cmd.(Timeouter); ok {
+		return t.Timeout()
+	}
+	return c.Timeout
+}
+
+// executeWithTimeout executes cmd with a deadline if one is configured and
+// maps an exceeded deadline to ExitTimeout.
+func (c *Commander) executeWithTimeout(ctx context.Context, cmd Command, exec func(context.Context) ExitStatus) ExitStatus {
+	timeout := c.timeout(cmd)
+	if timeout <= 0 {
+		return exec(ctx)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errCommandTimeout)
+	defer cancel()
+
+	// An expired deadline of the parent context is left to mapContextError.
+	status := exec(ctx)
+	if errors.Is(context.Cause(ctx), errCommandTimeout) {
+		fmt.Fprintf(c.Error, c.tr("Subcommand %s timed out after %s\n"), cmd.Name(), timeout)
+		return ExitTimeout
+	}
+	return status
+}
+
+// mapContextError overrides status if MapContextErrors is enabled and ctx
+// is done.
+func (c *Commander) mapContextError(ctx context.Context, cmd Command, status ExitStatus) ExitStatus {
+	if !c.MapContextErrors {
+		return status
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		fmt.Fprintf(c.Error, c.tr("Subcommand %s was cancelled\n"), cmd.Name())
+		return ExitCancelled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		fmt.Fprintf(c.Error, c.tr("Subcommand %s exceeded the deadline\n"), cmd.Name())
+		return ExitTimeout
+	}
+	return status
+}
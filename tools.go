@@ -0,0 +1,247 @@
+package psubcommands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ArgsParameter is the name of the tool parameter holding the positional
+// arguments of a command.
+const ArgsParameter = "args"
+
+// JSONSchema is the subset of JSON Schema used to describe tool parameters.
+type JSONSchema struct {
+	Type        string                 `json:"type,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	MinItems    *int                   `json:"minItems,omitempty"`
+	MaxItems    *int                   `json:"maxItems,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Default     interface{}            `json:"default,omitempty"`
+}
+
+// ToolSpec describes a command as a tool which can be called by a language
+// model, in the format used by function calling APIs.
+type ToolSpec struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  *JSONSchema `json:"parameters"`
+}
+
+var nonToolChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// toolName returns the name of the tool for cmd. Characters not allowed in
+// tool names, like a NamespaceSeparator, are replaced by underscores.
+func toolName(cmd Command) string { return nonToolChars.ReplaceAllString(cmd.Name(), "_") }
+
+// ExportTools returns a ToolSpec for every visible command. The parameters
+// are derived from the visible flags of a command and, if it accepts
+// positional arguments, the array parameter ArgsParameter. Use ToolCall to
+// turn the arguments of a call back into a command line.
+func (c *Commander) ExportTools() []*ToolSpec {
+	tools := []*ToolSpec{}
+	for _, g := range c.orderedGroups() {
+		for _, cmd := range g.commands {
+			tools = append(tools, c.exportTool(cmd))
+		}
+	}
+	return tools
+}
+
+func (c *Commander) exportTool(cmd Command) *ToolSpec {
+	cmd = unwrap(cmd)
+	description := c.synopsis(cmd)
+	if u, ok := cmd.(LongUsager); ok {
+		if usage := strings.TrimSpace(u.Usage()); usage != "" {
+			description += "\n\n" + usage
+		}
+	}
+
+	params := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, Required: []string{}}
+	c.commandFlags(cmd).VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		params.Properties[flag.Name] = flagSchema(flag)
+		if isRequired(flag) {
+			params.Required = append(params.Required, flag.Name)
+		}
+	})
+
+	if spec, ok := argSpec(cmd); ok && (spec.Max != 0 || spec.PassThrough != "") {
+		args := &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}}
+		if spec.Min > 0 {
+			args.MinItems = &spec.Min
+			params.Required = append(params.Required, ArgsParameter)
+		}
+		if spec.Max > 0 && spec.PassThrough == "" {
+			args.MaxItems = &spec.Max
+		}
+		args.Description = fmt.Sprintf(c.tr("positional arguments: %s"), spec)
+		params.Properties[ArgsParameter] = args
+	} else if !ok && !strictArgs(cmd) {
+		params.Properties[ArgsParameter] = &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}, Description: c.tr("positional arguments")}
+	}
+	sort.Strings(params.Required)
+
+	return &ToolSpec{Name: toolName(cmd), Description: description, Parameters: params}
+}
+
+// flagSchema returns the schema of the value of flag.
+func flagSchema(flag *pflag.Flag) *JSONSchema {
+	s := &JSONSchema{Type: schemaType(flag.Value.Type()), Description: flag.Usage}
+	if strings.HasSuffix(flag.Value.Type(), "Slice") || strings.HasSuffix(flag.Value.Type(), "Array") {
+		item := strings.TrimSuffix(strings.TrimSuffix(flag.Value.Type(), "Slice"), "Array")
+		s.Type, s.Items = "array", &JSONSchema{Type: schemaType(item)}
+	}
+	if values, ok := flag.Annotations[annotationEnum]; ok {
+		if s.Items != nil {
+			s.Items.Enum = values
+		} else {
+			s.Enum = values
+		}
+	}
+	if !isSecret(flag) {
+		switch flag.DefValue {
+		case "", "[]", "<nil>":
+		default:
+			s.Default = schemaValue(s.Type, flag.DefValue)
+		}
+	}
+	return s
+}
+
+// schemaType maps the type of a pflag.Value to a JSON Schema type.
+func schemaType(typ string) string {
+	switch {
+	case typ == "bool":
+		return "boolean"
+	case typ == "count", strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "uint"):
+		return "integer"
+	case strings.HasPrefix(typ, "float"):
+		return "number"
+	}
+	return "string"
+}
+
+// schemaValue converts the default value of a flag to typ if possible.
+func schemaValue(typ, value string) interface{} {
+	switch typ {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "array":
+		return nil
+	}
+	return value
+}
+
+// WriteTools writes the ToolSpecs of all visible commands to w as a JSON array.
+func (c *Commander) WriteTools(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(c.ExportTools())
+}
+
+// ToolCall converts a call of the tool name with the JSON encoded arguments
+// into a command line, which can be executed with ExecuteArgs. Only the
+// commands and flags described by ExportTools are accepted.
+func (c *Commander) ToolCall(name string, arguments []byte) ([]string, error) {
+	var cmd Command
+	for _, g := range c.orderedGroups() {
+		for _, v := range g.commands {
+			if cmd == nil && toolName(v) == name {
+				cmd = v
+			}
+		}
+	}
+	if cmd == nil {
+		return nil, &CommandError{Op: "call", Name: name, Err: ErrUnknownCommand}
+	}
+
+	params := map[string]interface{}{}
+	if len(arguments) > 0 {
+		dec := json.NewDecoder(strings.NewReader(string(arguments)))
+		dec.UseNumber()
+		if err := dec.Decode(&params); err != nil {
+			return nil, fmt.Errorf("psubcommands: call %s: %w", name, err)
+		}
+	}
+
+	argv := []string{cmd.Name()}
+	f := c.commandFlags(unwrap(cmd))
+	for _, key := range sortedKeys(params) {
+		if key == ArgsParameter {
+			continue
+		}
+		if flag := f.Lookup(key); flag == nil || flag.Hidden {
+			return nil, fmt.Errorf("psubcommands: call %s: unknown parameter %q", name, key)
+		}
+		values, ok := params[key].([]interface{})
+		if !ok {
+			values = []interface{}{params[key]}
+		}
+		for _, v := range values {
+			value, ok := toolValue(v)
+			if !ok {
+				return nil, fmt.Errorf("psubcommands: call %s: parameter %q must be a string, number or boolean", name, key)
+			}
+			argv = append(argv, fmt.Sprintf("--%s=%s", key, value))
+		}
+	}
+
+	if args, ok := params[ArgsParameter]; ok {
+		list, ok := args.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("psubcommands: call %s: parameter %q must be an array", name, ArgsParameter)
+		}
+		positional := make([]string, len(list))
+		dash := false
+		for i, v := range list {
+			value, ok := toolValue(v)
+			if !ok {
+				return nil, fmt.Errorf("psubcommands: call %s: parameter %q must contain strings, numbers or booleans", name, ArgsParameter)
+			}
+			positional[i] = value
+			dash = dash || strings.HasPrefix(positional[i], "-")
+		}
+		if dash {
+			argv = append(argv, "--")
+		}
+		argv = append(argv, positional...)
+	}
+	return argv, nil
+}
+
+// toolValue formats a scalar JSON value as a command line argument. It
+// returns false for null, objects and nested arrays.
+func toolValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package psubcommands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type helpTopic struct {
+	name     string
+	synopsis string
+	text     string
+}
+
+// RegisterHelpTopic registers a help topic which isn't an executable command
+// but shown by the help command, e.g. "tool help environment". Topics are
+// listed in an "Additional help topics" section of the help overview.
+func (c *Commander) RegisterHelpTopic(name, synopsis, text string) {
+	for _, t := range c.topics {
+		if t.name == name {
+			t.synopsis, t.text = synopsis, text
+			return
+		}
+	}
+	c.topics = append(c.topics, &helpTopic{name: name, synopsis: synopsis, text: text})
+}
+
+// RegisterHelpTopic registers a help topic on the DefaultCommander.
+func RegisterHelpTopic(name, synopsis, text string) {
+	DefaultCommander.RegisterHelpTopic(name, synopsis, text)
+}
+
+func (c *Commander) lookupTopic(name string) (*helpTopic, bool) {
+	for _, t := range c.topics {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (c *Commander) explainTopics(w io.Writer) {
+	if len(c.topics) == 0 {
+		return
+	}
+	fmt.Fprint(w, c.tr("Additional help topics:\n"))
+	for _, t := range c.topics {
+		fmt.Fprint(w, overviewLine(w, t.name, t.synopsis))
+	}
+	fmt.Fprintln(w)
+}
+
+func (c *Commander) explainTopic(w io.Writer, t *helpTopic) {
+	fmt.Fprintf(w, "%s\n", strings.TrimRight(t.text, "\n"))
+}
@@ -0,0 +1,93 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+type treeNode struct {
+	name     string
+	synopsis string
+	children []*treeNode
+}
+
+// tree returns the visible command hierarchy: groups, namespaces and commands.
+func (c *Commander) tree() *treeNode {
+	root := &treeNode{name: filepath.Base(c.name)}
+	for _, g := range c.orderedGroups() {
+		parent := root
+		if g.name != "" {
+			parent = &treeNode{name: g.name, synopsis: g.description}
+			root.children = append(root.children, parent)
+		}
+
+		plain, namespaces, byNamespace := c.splitNamespaces(g.commands)
+		for _, cmd := range plain {
+			parent.children = append(parent.children, &treeNode{name: cmd.Name(), synopsis: cmd.Synopsis()})
+		}
+		for _, ns := range namespaces {
+			node := &treeNode{name: ns}
+			for _, cmd := range byNamespace[ns] {
+				node.children = append(node.children, &treeNode{name: cmd.Name(), synopsis: cmd.Synopsis()})
+			}
+			parent.children = append(parent.children, node)
+		}
+	}
+	return root
+}
+
+// PrintTree writes the hierarchy of all visible groups, namespaces and
+// commands with their synopses as indented tree to w.
+func (c *Commander) PrintTree(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	var print func(node *treeNode, prefix string)
+	print = func(node *treeNode, prefix string) {
+		for i, child := range node.children {
+			branch, next := "├── ", "│   "
+			if i == len(node.children)-1 {
+				branch, next = "└── ", "    "
+			}
+			fmt.Fprintf(tw, "%s%s%s\t%s\n", prefix, branch, child.name, child.synopsis)
+			print(child, prefix+next)
+		}
+	}
+
+	root := c.tree()
+	fmt.Fprintf(tw, "%s\t\n", root.name)
+	print(root, "")
+	return tw.Flush()
+}
+
+type treeCommand Commander
+
+// Name of this command.
+func (*treeCommand) Name() string { return "tree" }
+
+// Synopsis returns a short description of this command.
+func (t *treeCommand) Synopsis() string {
+	return (*Commander)(t).tr("print all commands as tree")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*treeCommand) SetFlags(*pflag.FlagSet) {}
+
+// Execute executs this command and returns it's ExitStatus.
+func (t *treeCommand) Execute(ctx context.Context, _ *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	if err := (*Commander)(t).PrintTree(Streams(ctx).Out); err != nil {
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// RegisterTreeCommand registers the "tree" command printing the command
+// hierarchy to the specified group.
+func (c *Commander) RegisterTreeCommand(group string) { c.Register(group, (*treeCommand)(c)) }
+
+// RegisterTreeCommand registers the tree command on the DefaultCommander.
+func RegisterTreeCommand(group string) { DefaultCommander.RegisterTreeCommand(group) }
@@ -0,0 +1,66 @@
+package psubcommands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// UnknownFlagsAllower may be implemented by a Command which accepts flags it
+// doesn't declare, e.g. to forward them to an underlying tool. Unknown flags
+// are removed from the command line before parsing and can be retrieved
+// with UnknownFlags.
+type UnknownFlagsAllower interface {
+	AllowUnknownFlags() bool
+}
+
+func allowsUnknownFlags(cmd Command) bool {
+	a, ok := cmd.(UnknownFlagsAllower)
+	return ok && a.AllowUnknownFlags()
+}
+
+// UnknownFlags returns the flags not declared by the current command in the
+// order they appeared on the command line. Like pflag, a value following an
+// unknown flag without "=" is considered to belong to that flag unless it
+// looks like a flag itself.
+func UnknownFlags(ctx context.Context) []string {
+	flags, _ := ctx.Value(unknownFlagsKey).([]string)
+	return flags
+}
+
+// splitUnknownFlags separates argv into arguments known to f and unknown flags.
+func splitUnknownFlags(f *pflag.FlagSet, argv []string) (known, unknown []string) {
+	known, unknown = []string{}, []string{}
+
+	for i := 0; i < len(argv); i++ {
+		arg := argv[i]
+		if arg == "--" {
+			known = append(known, argv[i:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			known = append(known, arg)
+			continue
+		}
+
+		var flag *pflag.Flag
+		if strings.HasPrefix(arg, "--") {
+			flag = f.Lookup(strings.SplitN(arg[2:], "=", 2)[0])
+		} else {
+			flag = f.ShorthandLookup(arg[1:2])
+		}
+		if flag != nil {
+			known = append(known, arg)
+			continue
+		}
+
+		unknown = append(unknown, arg)
+		if !strings.Contains(arg, "=") && i+1 < len(argv) && !strings.HasPrefix(argv[i+1], "-") {
+			i++
+			unknown = append(unknown, argv[i])
+		}
+	}
+
+	return known, unknown
+}
@@ -0,0 +1,29 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Validator may be implemented by a Command to validate its flags and
+// arguments after parsing. An error is reported as usage error followed by
+// the usage of the command, and the command isn't executed.
+type Validator interface {
+	Validate(ctx context.Context, f *pflag.FlagSet) error
+}
+
+// validate calls the Validator of cmd, if any.
+func (c *Commander) validate(ctx context.Context, cmd Command, f *pflag.FlagSet) ExitStatus {
+	v, ok := cmd.(Validator)
+	if !ok {
+		return ExitSuccess
+	}
+	if err := v.Validate(ctx, f); err != nil {
+		fmt.Fprintf(f.Output(), c.tr("Subcommand %s: %s\n\n"), cmd.Name(), err)
+		c.ExplainCommand(f.Output(), cmd)
+		return ExitUsageError
+	}
+	return ExitSuccess
+}
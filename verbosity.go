@@ -0,0 +1,43 @@
+package psubcommands
+
+import (
+	"context"
+)
+
+const (
+	// VerboseFlag is the name of the flag registered by EnableVerbosityFlags
+	// to increase verbosity.
+	VerboseFlag = "verbose"
+	// QuietFlag is the name of the flag registered by EnableVerbosityFlags
+	// to suppress output.
+	QuietFlag = "quiet"
+)
+
+// EnableVerbosityFlags registers the top-level flags -v/--verbose, which may
+// be repeated, and -q/--quiet. The resulting level is available to commands
+// through Verbosity.
+func (c *Commander) EnableVerbosityFlags() {
+	c.topFlags.CountP(VerboseFlag, "v", c.tr("increase verbosity, may be repeated"))
+	c.topFlags.BoolP(QuietFlag, "q", false, c.tr("suppress non-essential output"))
+	c.verbosity = true
+}
+
+// EnableVerbosityFlags registers the verbosity flags on the DefaultCommander.
+func EnableVerbosityFlags() { DefaultCommander.EnableVerbosityFlags() }
+
+// verbosityLevel returns the level selected by the verbosity flags.
+func (c *Commander) verbosityLevel() int {
+	if quiet, _ := c.topFlags.GetBool(QuietFlag); quiet {
+		return -1
+	}
+	level, _ := c.topFlags.GetCount(VerboseFlag)
+	return level
+}
+
+// Verbosity returns the verbosity level of the current command: -1 if
+// --quiet was given, otherwise the number of times --verbose was given.
+// It returns 0 if the verbosity flags aren't enabled.
+func Verbosity(ctx context.Context) int {
+	level, _ := ctx.Value(verbosityKey).(int)
+	return level
+}
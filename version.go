@@ -0,0 +1,90 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// BuildInfo returns the version, VCS revision and build time of the program.
+// Values set on the Commander take precedence over the build info embedded
+// by the Go toolchain. A revision built from a modified tree is suffixed
+// with "-dirty".
+func (c *Commander) BuildInfo() (version, revision, buildTime string) {
+	version, revision, buildTime = c.Version, c.Revision, c.BuildTime
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	if version == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	settings := map[string]string{}
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+	if revision == "" && settings["vcs.revision"] != "" {
+		revision = settings["vcs.revision"]
+		if settings["vcs.modified"] == "true" {
+			revision += "-dirty"
+		}
+	}
+	if buildTime == "" {
+		buildTime = settings["vcs.time"]
+	}
+	return
+}
+
+// versionLine returns a single line describing the build, e.g.
+// "tool v1.2.0 (rev 0123abc, built 2024-01-01T00:00:00Z)".
+func (c *Commander) versionLine() string {
+	version, revision, buildTime := c.BuildInfo()
+	if version == "" {
+		version = c.tr("(devel)")
+	}
+
+	details := []string{}
+	if revision != "" {
+		details = append(details, fmt.Sprintf(c.tr("rev %s"), revision))
+	}
+	if buildTime != "" {
+		details = append(details, fmt.Sprintf(c.tr("built %s"), buildTime))
+	}
+
+	line := filepath.Base(c.name) + " " + version
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return line
+}
+
+type versionCommand Commander
+
+// Name of this command.
+func (*versionCommand) Name() string { return "version" }
+
+// Synopsis returns a short description of this command.
+func (v *versionCommand) Synopsis() string {
+	return (*Commander)(v).tr("print version information")
+}
+
+// SetFlags adds the flags to the FlagSet.
+func (*versionCommand) SetFlags(*pflag.FlagSet) {}
+
+// Execute executs this command and returns it's ExitStatus.
+func (v *versionCommand) Execute(ctx context.Context, _ *pflag.FlagSet, _ ...interface{}) ExitStatus {
+	fmt.Fprintln(Streams(ctx).Out, (*Commander)(v).versionLine())
+	return ExitSuccess
+}
+
+// RegisterVersionCommand registers the "version" command printing the
+// version, revision and build time to the specified group.
+func (c *Commander) RegisterVersionCommand(group string) { c.Register(group, (*versionCommand)(c)) }
+
+// RegisterVersionCommand registers the version command on the DefaultCommander.
+func RegisterVersionCommand(group string) { DefaultCommander.RegisterVersionCommand(group) }
@@ -0,0 +1,97 @@
+package psubcommands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// WorkDirer may be implemented by a Command which must run in a specific
+// working directory. It takes precedence over Commander.WorkDir.
+type WorkDirer interface {
+	WorkDir() string
+}
+
+// EnvOverrider may be implemented by a Command to set environment variables
+// while it runs. They take precedence over Commander.Env.
+type EnvOverrider interface {
+	EnvOverrides() map[string]string
+}
+
+// processMu serializes commands changing the working directory or the
+// environment of the process. Commands dispatched while it is held, e.g.
+// by the shell command, find processLockKey in their context and don't
+// lock it again.
+var processMu sync.Mutex
+
+// overrides returns the working directory and environment cmd runs with.
+func (c *Commander) overrides(cmd Command) (string, map[string]string) {
+	dir := c.WorkDir
+	if w, ok := cmd.(WorkDirer); ok && w.WorkDir() != "" {
+		dir = w.WorkDir()
+	}
+
+	env := copyMap(c.Env)
+	if e, ok := cmd.(EnvOverrider); ok {
+		for k, v := range e.EnvOverrides() {
+			if env == nil {
+				env = map[string]string{}
+			}
+			env[k] = v
+		}
+	}
+	return dir, env
+}
+
+// executeWithOverrides runs fn in the working directory and with the
+// environment overrides of cmd, restoring both afterwards. As they affect
+// the whole process, commands with overrides don't run concurrently with
+// each other. Commands without overrides running at the same time, e.g.
+// with ExecuteAll, see the changed working directory and environment.
+func (c *Commander) executeWithOverrides(ctx context.Context, cmd Command, fn func(ctx context.Context) ExitStatus) ExitStatus {
+	dir, env := c.overrides(cmd)
+	if dir == "" && len(env) == 0 {
+		return fn(ctx)
+	}
+
+	if ctx.Value(processLockKey) == nil {
+		processMu.Lock()
+		defer processMu.Unlock()
+		ctx = context.WithValue(ctx, processLockKey, true)
+	}
+
+	if dir != "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(c.Error, c.tr("Failed to get working directory: %s\n"), err)
+			return ExitFailure
+		}
+		if err := os.Chdir(dir); err != nil {
+			fmt.Fprintf(c.Error, c.tr("Failed to change working directory: %s\n"), err)
+			return StatusFromError(err)
+		}
+		defer os.Chdir(wd)
+	}
+
+	for _, key := range sortedKeys(env) {
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, env[key])
+		if ok {
+			defer os.Setenv(key, old)
+		} else {
+			defer os.Unsetenv(key)
+		}
+	}
+
+	if len(env) > 0 {
+		lookup := func(key string) (string, bool) { return LookupEnv(ctx, key) }
+		ctx = WithEnv(ctx, func(key string) (string, bool) {
+			if v, ok := env[key]; ok {
+				return v, true
+			}
+			return lookup(key)
+		})
+	}
+	return fn(ctx)
+}